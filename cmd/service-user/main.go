@@ -1,23 +1,80 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/gin-gonic/gin"
 	"github.com/rahul-aut-ind/service-user/infrastructure/app"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/internal/secrets"
 	"github.com/rahul-aut-ind/service-user/pkg/logger"
 )
 
 func main() {
+	mode := flag.String("mode", string(config.ModeService), "service uses the configured databases, "+
+		"standalone runs with SQLite, an in-memory cache and local image storage")
+	file := flag.String("config", "", "yaml config file, env variables and flags override its settings. "+
+		"Defaults to the "+config.EnvConfigFile+" env variable")
+	overrides := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
 	log := logger.New()
+	env, err := config.Load(config.Mode(*mode), *file, overrides, secrets.NewResolver())
+
+	// service-user [flags] config print [--redact]
+	if flag.Arg(0) == "config" {
+		if printErr := printConfig(env, flag.Args()[1:]); printErr != nil {
+			log.Fatal(printErr)
+		}
+		exitIfInvalid(err)
+		return
+	}
+	exitIfInvalid(err)
+
 	log.Info(">>>>>   service-user   <<<<<<")
+	// service-user [flags] migrate up|down|status
+	if flag.Arg(0) == "migrate" {
+		if err := app.Migrate(env, flag.Arg(1), os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	gin.SetMode("release")
 	e := gin.New()
+	// lets the gin context passed down to services and repos end with the request,
+	// a client going away cancels the downstream calls
+	e.ContextWithFallback = true
 	e.Use(gin.Recovery())
-	e.Use(log.DefaultLogger())
 
-	a, err := app.New(e)
+	a, err := app.New(e, env)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	a.Start()
 }
+
+// printConfig writes the loaded config as yaml to stdout, with --redact its secrets are masked
+func printConfig(env *config.Env, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("unknown config command %q, use print [--redact]", args)
+	}
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	redact := fs.Bool("redact", false, "mask secrets and the passwords of connection strings")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	return env.Print(os.Stdout, *redact)
+}
+
+// exitIfInvalid stops the service before it boots if the config has any invalid setting, listing all of them
+func exitIfInvalid(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+	os.Exit(1)
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -17,6 +18,20 @@ const (
 	ErrCodeNotFound = "NotFound"
 	// ErrCodeNoUser API Error code for no user exists
 	ErrCodeNoUser = "NoUserFound"
+	// ErrCodeUnauthorized API Error code for missing or wrong credentials
+	ErrCodeUnauthorized = "Unauthorized"
+	// ErrCodeQuotaExceeded API Error code for a user over the storage quota
+	ErrCodeQuotaExceeded = "QuotaExceeded"
+	// ErrCodeTooManyRequests API Error code for a client over its rate limit
+	ErrCodeTooManyRequests = "TooManyRequests"
+	// ErrCodeConflict API Error code for a request conflicting with one still running
+	ErrCodeConflict = "Conflict"
+	// ErrCodeIdempotencyKeyReused API Error code for an Idempotency-Key sent again with another request
+	ErrCodeIdempotencyKeyReused = "IdempotencyKeyReused"
+	// ErrCodePayloadTooLarge API Error code for a request body over the upload limit
+	ErrCodePayloadTooLarge = "PayloadTooLarge"
+	// ErrCodeServiceUnavailable API Error code for a dependency that is down or overloaded
+	ErrCodeServiceUnavailable = "ServiceUnavailable"
 	// The added to all error codes to prevent conflicting with other services
 	errorMessageKeyPrefix = "service-user"
 )
@@ -41,10 +56,17 @@ func (e Error) Error() string {
 
 func (e Error) HTTPCode() int {
 	errCodeMap := map[string]int{
-		ErrCodeBadRequest: http.StatusBadRequest,
-		ErrCodeGeneric:    http.StatusInternalServerError,
-		ErrCodeNoUser:     http.StatusNotFound,
-		ErrCodeNotFound:   http.StatusNotFound,
+		ErrCodeBadRequest:           http.StatusBadRequest,
+		ErrCodeGeneric:              http.StatusInternalServerError,
+		ErrCodeNoUser:               http.StatusNotFound,
+		ErrCodeNotFound:             http.StatusNotFound,
+		ErrCodeQuotaExceeded:        http.StatusForbidden,
+		ErrCodeUnauthorized:         http.StatusUnauthorized,
+		ErrCodeTooManyRequests:      http.StatusTooManyRequests,
+		ErrCodeConflict:             http.StatusConflict,
+		ErrCodeIdempotencyKeyReused: http.StatusUnprocessableEntity,
+		ErrCodeServiceUnavailable:   http.StatusServiceUnavailable,
+		ErrCodePayloadTooLarge:      http.StatusRequestEntityTooLarge,
 	}
 	if code, ok := errCodeMap[e.Code]; ok {
 		return code
@@ -52,3 +74,20 @@ func (e Error) HTTPCode() int {
 
 	return http.StatusInternalServerError
 }
+
+// IsClientError reports whether err is answered with a 4xx like a missing record or an exceeded quota,
+// saying nothing about the health of the backend that returned it
+func IsClientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// the user database reports a missing user by the code in the message
+	if strings.Contains(err.Error(), ErrCodeNoUser) {
+		return true
+	}
+	var e Error
+	if stderrors.As(err, &e) {
+		return e.HTTPCode() < http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type (
+	Album struct {
+		UserID    string    `json:"userId" validate:"required"`
+		AlbumID   string    `json:"albumId" validate:"required"`
+		Name      string    `json:"name" validate:"required"`
+		CreatedAt time.Time `json:"createdAt" validate:"required"`
+		UpdatedAt time.Time `json:"updatedAt" validate:"required"`
+	}
+
+	AlbumRequest struct {
+		Name string `json:"name" validate:"required,min=1,max=100"`
+	}
+
+	AlbumResponse struct {
+		AlbumID   string    `json:"id"`
+		Name      string    `json:"name"`
+		CreatedAt time.Time `json:"createdAt"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	}
+
+	AlbumListResponse struct {
+		Albums []AlbumResponse `json:"items"`
+	}
+
+	TagsRequest struct {
+		Tags []string `json:"tags" validate:"max=20,dive,min=1,max=50"`
+	}
+)
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+type (
+	// ArchiveInput selects the images of a user that go into a zip archive
+	ArchiveInput struct {
+		UserID string
+		// From optionally excludes images taken before it
+		From *time.Time
+		// To optionally excludes images taken after it
+		To *time.Time
+	}
+
+	// ArchiveIndexEntry describes one image of a zip archive in its index file
+	ArchiveIndexEntry struct {
+		File    string    `json:"file"`
+		ImageID string    `json:"id"`
+		TakenAt time.Time `json:"takenAt"`
+		Size    int64     `json:"size,omitempty"`
+		Tags    []string  `json:"tags,omitempty"`
+	}
+)
+
+// Includes reports if an image taken at the given time falls into the requested range
+func (a *ArchiveInput) Includes(takenAt time.Time) bool {
+	return TakenBetween(takenAt, a.From, a.To)
+}
+
+// TakenBetween reports if takenAt is neither before the optional from nor after the optional to
+func TakenBetween(takenAt time.Time, from, to *time.Time) bool {
+	if from != nil && takenAt.Before(*from) {
+		return false
+	}
+	if to != nil && takenAt.After(*to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+type (
+	// Share grants read access to an image, either to another user or to anyone holding a public link
+	Share struct {
+		ShareID      string     `json:"shareId" validate:"required"`
+		OwnerID      string     `json:"ownerId" validate:"required"`
+		ImageID      string     `json:"imageId" validate:"required"`
+		GranteeID    string     `json:"granteeId,omitempty" dynamodbav:",omitempty"`
+		PasswordHash string     `json:"-" dynamodbav:",omitempty"`
+		ExpiresAt    *time.Time `json:"expiresAt,omitempty" dynamodbav:",omitempty"`
+		CreatedAt    time.Time  `json:"createdAt" validate:"required"`
+		// TTL lets dynamoDB purge expired public links, in epoch seconds
+		TTL int64 `json:"-" dynamodbav:",omitempty"`
+	}
+
+	ShareRequest struct {
+		UserID    string     `json:"userId" validate:"omitempty,numeric"`
+		ExpiresAt *time.Time `json:"expiresAt" validate:"required_without=UserID"`
+		Password  string     `json:"password" validate:"omitempty,min=6,max=72"`
+	}
+
+	ShareResponse struct {
+		ShareID     string     `json:"id"`
+		ImageID     string     `json:"imageId"`
+		UserID      string     `json:"userId,omitempty"`
+		URL         string     `json:"url,omitempty"`
+		ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+		HasPassword bool       `json:"hasPassword"`
+		CreatedAt   time.Time  `json:"createdAt"`
+	}
+
+	ShareListResponse struct {
+		Shares []ShareResponse `json:"items"`
+	}
+)
+
+// IsPublicLink tells a public link apart from a grant to another user
+func (s *Share) IsPublicLink() bool {
+	return s.GranteeID == ""
+}
+
+// IsExpired reports if the share can no longer be used at the given time
+func (s *Share) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
@@ -0,0 +1,22 @@
+package models
+
+type (
+	// Usage is the storage consumed by the images of a user
+	Usage struct {
+		UsedBytes  int64
+		ImageCount int64
+	}
+
+	// Quota is the storage a user is allowed to consume
+	Quota struct {
+		MaxBytes  int64
+		MaxImages int64
+	}
+
+	UsageResponse struct {
+		UsedBytes  int64 `json:"usedBytes"`
+		ImageCount int64 `json:"imageCount"`
+		MaxBytes   int64 `json:"maxBytes"`
+		MaxImages  int64 `json:"maxImages"`
+	}
+)
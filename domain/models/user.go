@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 )
 
@@ -27,3 +29,17 @@ type (
 		Age       int    `json:"age" validate:"gte=18,lte=100"`
 	}
 )
+
+// redacted stands in for personal data when a user is formatted, matching the log redaction
+const redacted = "[REDACTED]"
+
+// String leaves out the email and address, so a user formatted into a log line or an error
+// does not leak them
+func (u User) String() string {
+	return fmt.Sprintf("{ID:%d Name:%s Email:%s Address:%s Age:%d}", u.ID, u.Name, redacted, redacted, u.Age)
+}
+
+// String leaves out the email and address, like User.String
+func (r Request) String() string {
+	return fmt.Sprintf("{FirstName:%s LastName:%s Email:%s Address:%s Age:%d}", r.FirstName, r.LastName, redacted, redacted, r.Age)
+}
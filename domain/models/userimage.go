@@ -10,6 +10,9 @@ type (
 		Path      string    `json:"path" validate:"required"`
 		TakenAt   time.Time `json:"takenAt" validate:"required"`
 		UpdatedAt time.Time `json:"updatedAt" validate:"required"`
+		Size      int64     `json:"size"`
+		Tags      []string  `json:"tags" dynamodbav:",stringset,omitempty"`
+		AlbumIDs  []string  `json:"albumIds" dynamodbav:",stringset,omitempty"`
 	}
 
 	UserImageResult struct {
@@ -26,9 +29,13 @@ type (
 	}
 
 	ImageResponse struct {
-		ImageID string    `json:"id"`
-		Path    string    `json:"path"`
-		TakenAt time.Time `json:"takenAt"`
+		ImageID  string    `json:"id"`
+		Path     string    `json:"path"`
+		TakenAt  time.Time `json:"takenAt"`
+		Tags     []string  `json:"tags,omitempty"`
+		AlbumIDs []string  `json:"albumIds,omitempty"`
+		// URL is a pre-signed url of the image, only set for images shared with the requester
+		URL string `json:"url,omitempty"`
 	}
 
 	PaginatedImageResponse struct {
@@ -41,10 +48,15 @@ type (
 		LastImageID      string
 		LastImageTakenAt string
 		Limit            int32
+		// AlbumID optionally restricts the result to images of an album
+		AlbumID string
+		// Tag optionally restricts the result to images carrying the tag
+		Tag string
 	}
 
 	Metadata struct {
 		TakenAt time.Time `json:"takenAt" validate:"required"`
 		Type    string    `json:"type" validate:"required"`
+		Tags    []string  `json:"tags" validate:"max=20,dive,min=1,max=50"`
 	}
 )
@@ -1,9 +1,17 @@
 package app
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/infrastructure/lifecycle"
+	"github.com/rahul-aut-ind/service-user/infrastructure/rotation"
 	"github.com/rahul-aut-ind/service-user/infrastructure/routes"
 	"github.com/rahul-aut-ind/service-user/internal/config"
 	"github.com/rahul-aut-ind/service-user/pkg/logger"
@@ -12,18 +20,77 @@ import (
 type App struct {
 	route  *routes.Routes
 	engine *gin.Engine
+	lc     *lifecycle.Lifecycle
 	env    *config.Env
 	log    *logger.Logger
 }
 
-func newApp(r *routes.Routes, env *config.Env, l *logger.Logger, e *gin.Engine) *App {
-	return &App{route: r, env: env, log: l, engine: e}
+func newApp(r *routes.Routes, env *config.Env, l *logger.Logger, e *gin.Engine, lc *lifecycle.Lifecycle, rot *rotation.Rotation) *App {
+	// appended after the backends, so secrets stop rotating before the backends are closed
+	if env.SecretsRefreshInterval > 0 {
+		lc.Append(rot.Hook())
+	}
+	return &App{route: r, env: env, log: l, engine: e, lc: lc}
 }
 
+// Start serves requests until the process gets SIGINT or SIGTERM. On a signal the server stops
+// accepting connections and in-flight requests get ShutdownTimeout to finish, then the
+// connections to the backends are closed.
 func (a *App) Start() {
 	a.route.Setup()
-	err := a.engine.Run(fmt.Sprintf("%s:%s", a.env.ServerHost, a.env.ServerPort))
-	if err != nil {
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	a.lc.Append(a.serverHook(serveErr))
+
+	if err := a.lc.Start(ctx); err != nil {
 		a.log.Fatalf("could not start the server | err :: %v", err)
 	}
+
+	select {
+	case <-ctx.Done():
+		a.log.Infof("shutting down, waiting up to %s for in-flight requests", a.env.ShutdownTimeout)
+	case err := <-serveErr:
+		a.log.Errorf("server stopped unexpectedly | err :: %v", err)
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.env.ShutdownTimeout)
+	defer cancel()
+	if err := a.lc.Stop(shutdownCtx); err != nil {
+		a.log.Fatalf("could not shut down cleanly | err :: %v", err)
+	}
+	a.log.Info("shutdown complete")
+}
+
+// serverHook listens when started, so a port in use fails the start, and serves in the background.
+// Errors of the running server are sent to serveErr.
+func (a *App) serverHook(serveErr chan<- error) lifecycle.Hook {
+	srv := &http.Server{
+		Addr:         fmt.Sprintf("%s:%s", a.env.ServerHost, a.env.ServerPort),
+		Handler:      a.engine,
+		ReadTimeout:  a.env.ServerReadTimeout,
+		WriteTimeout: a.env.ServerWriteTimeout,
+		IdleTimeout:  a.env.ServerIdleTimeout,
+	}
+
+	return lifecycle.Hook{
+		Name: "http server",
+		OnStart: func(context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			a.log.Infof("listening on %s", ln.Addr())
+			go func() {
+				if err := srv.Serve(ln); !stderrors.Is(err, http.ErrServerClosed) {
+					serveErr <- err
+				}
+			}()
+			return nil
+		},
+		OnStop: srv.Shutdown,
+	}
 }
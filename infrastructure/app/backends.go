@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/infrastructure/lifecycle"
+	"github.com/rahul-aut-ind/service-user/infrastructure/metrics"
+	"github.com/rahul-aut-ind/service-user/infrastructure/rotation"
+	"github.com/rahul-aut-ind/service-user/infrastructure/tracing"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/fsrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/memrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/postgresrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/sqliterepo"
+	"github.com/rahul-aut-ind/service-user/internal/awsconfig"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// newImageDB picks where image records are kept from the config, dynamoDB unless memory is selected
+func newImageDB(l *logger.Logger, cfg *awsconfig.AWSConfig, env *config.Env, lc *lifecycle.Lifecycle, m *metrics.Metrics, t *tracing.Tracing) dynamorepo.DataHandler {
+	var db dynamorepo.DataHandler
+	switch env.ImageDB {
+	case config.ImageDBMemory:
+		l.Warn("keeping image records in memory, they are lost on restart")
+		db = memrepo.New(l)
+	case config.ImageDBDynamo:
+		db = closeOnStop(lc, "dynamodb", dynamorepo.New(cfg, env, l))
+	default:
+		l.Fatalf("unknown image db %s", env.ImageDB)
+		return nil
+	}
+	return tracing.NewImageDB(t, env.ImageDB, metrics.NewImageDB(m, env.ImageDB, db))
+}
+
+// newImageStorage picks where image files are kept from the config, S3 unless the local filesystem is selected
+func newImageStorage(l *logger.Logger, cfg *awsconfig.AWSConfig, env *config.Env, lc *lifecycle.Lifecycle, m *metrics.Metrics, t *tracing.Tracing) s3repo.S3Handler {
+	var storage s3repo.S3Handler
+	switch env.ImageStorage {
+	case config.ImageStorageFS:
+		l.Infof("storing images in local directory %s", env.LocalStorageDir)
+		storage = fsrepo.New(l, env)
+	case config.ImageStorageS3:
+		storage = closeOnStop(lc, "s3", s3repo.New(l, cfg, env))
+	default:
+		l.Fatalf("unknown image storage %s", env.ImageStorage)
+		return nil
+	}
+	return tracing.NewImageStorage(t, env.ImageStorage, metrics.NewImageStorage(m, env.ImageStorage, storage))
+}
+
+// userDBDialector opens the user database chosen by the scheme of the connection string
+func userDBDialector(env *config.Env) gorm.Dialector {
+	switch config.DBDriver(env.DBConnectionString) {
+	case config.DBDriverPostgres:
+		return postgresrepo.Dialector(env)
+	case config.DBDriverSQLite:
+		return sqliterepo.Dialector(env)
+	default:
+		return mysqlrepo.Dialector(env)
+	}
+}
+
+// newUserDB picks the user database from the scheme of the connection string
+func newUserDB(l *logger.Logger, env *config.Env, lc *lifecycle.Lifecycle, m *metrics.Metrics, t *tracing.Tracing, rot *rotation.Rotation) mysqlrepo.DataHandler {
+	var db mysqlrepo.DataHandler
+	driver := config.DBDriver(env.DBConnectionString)
+	switch driver {
+	case config.DBDriverPostgres:
+		db = closeOnStop(lc, driver, rotatingUserDB(rot, l, postgresrepo.New(l, env), postgresrepo.Open))
+	case config.DBDriverSQLite:
+		l.Infof("keeping users in SQLite database %s", config.TrimDBScheme(env.DBConnectionString))
+		db = closeOnStop(lc, driver, sqliterepo.New(l, env))
+	default:
+		db = closeOnStop(lc, driver, rotatingUserDB(rot, l, mysqlrepo.New(l, env), mysqlrepo.Open))
+	}
+	return tracing.NewUserDB(t, driver, metrics.NewUserDB(m, driver, db))
+}
+
+// rotatingUserDB rebuilds the connection pools of the user database when its connection strings rotate
+func rotatingUserDB(rot *rotation.Rotation, l *logger.Logger, client *mysqlrepo.GormClient, open func(dsn string) gorm.Dialector) *mysqlrepo.Rotating {
+	db := mysqlrepo.NewRotating(client, func(env *config.Env) (*mysqlrepo.GormClient, error) {
+		// the schema was migrated on boot already
+		reconnect := *env
+		reconnect.AutoMigrate = false
+		return mysqlrepo.DialRouted(open, &reconnect, l)
+	}, l)
+
+	rot.Subscribe("user database", func(applied, next *config.Env) bool {
+		return applied.DBConnectionString != next.DBConnectionString ||
+			!slices.Equal(applied.DBReplicaConnectionStrings, next.DBReplicaConnectionStrings)
+	}, db.Reconnect)
+	return db
+}
+
+// newAWSConfig loads the AWS config, switching the clients to new access keys when they rotate
+func newAWSConfig(env *config.Env, rot *rotation.Rotation) *awsconfig.AWSConfig {
+	cfg := awsconfig.NewAWSConfig(env)
+	rot.Subscribe("AWS credentials", func(applied, next *config.Env) bool {
+		return applied.AwsAccessKey != next.AwsAccessKey ||
+			applied.AwsSecretAccessKey != next.AwsSecretAccessKey ||
+			applied.AwsSessionToken != next.AwsSessionToken
+	}, cfg.Rotate)
+	return cfg
+}
+
+// cacheBackend is the redis or in-memory cache, holding the user cache, the rate limit counters
+// and the responses kept for idempotency keys
+type cacheBackend interface {
+	caching.IdempotencyStore
+	caching.RateLimiter
+}
+
+// newCacheBackend picks the cache from the config, redis unless memory is selected
+func newCacheBackend(l *logger.Logger, env *config.Env, lc *lifecycle.Lifecycle) cacheBackend {
+	switch env.Cache {
+	case config.CacheMemory:
+		return caching.NewMemoryCache(l)
+	case config.CacheRedis:
+		return closeOnStop(lc, "redis", caching.New(env, l))
+	default:
+		l.Fatalf("unknown cache %s", env.Cache)
+		return nil
+	}
+}
+
+// newCache caches users in the cache backend
+func newCache(backend cacheBackend, env *config.Env, m *metrics.Metrics, t *tracing.Tracing) caching.CacheHandler {
+	return tracing.NewCache(t, env.Cache, metrics.NewCache(m, env.Cache, backend))
+}
+
+// newRateLimiter counts the rate limits in the cache backend, shared by all instances with redis
+func newRateLimiter(backend cacheBackend) caching.RateLimiter {
+	return backend
+}
+
+// newIdempotencyStore keeps the responses for idempotency keys in the cache backend
+func newIdempotencyStore(backend cacheBackend) caching.IdempotencyStore {
+	return backend
+}
+
+// closeOnStop closes the connections of the backend when the app stops
+func closeOnStop[T io.Closer](lc *lifecycle.Lifecycle, name string, backend T) T {
+	lc.Append(lifecycle.Hook{
+		Name:   name,
+		OnStop: func(context.Context) error { return backend.Close() },
+	})
+	return backend
+}
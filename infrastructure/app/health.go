@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/infrastructure/health"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+// newHealthChecker checks the backends picked from the config, named by their role
+func newHealthChecker(
+	l *logger.Logger,
+	env *config.Env,
+	userDB mysqlrepo.DataHandler,
+	cache caching.CacheHandler,
+	imageDB dynamorepo.DataHandler,
+	storage s3repo.S3Handler,
+) *health.Checker {
+	deps := []struct {
+		name    string
+		backend string
+		dep     any
+	}{
+		{"userDB", config.DBDriver(env.DBConnectionString), userDB},
+		{"cache", env.Cache, cache},
+		{"imageDB", env.ImageDB, imageDB},
+		{"imageStorage", env.ImageStorage, storage},
+	}
+
+	checks := make([]health.Check, 0, len(deps))
+	for _, d := range deps {
+		p, ok := d.dep.(health.Pinger)
+		if !ok {
+			l.Warnf("%s %s can not be pinged, leaving it out of the readiness checks", d.name, d.backend)
+			continue
+		}
+		checks = append(checks, health.Check{Name: d.name, Backend: d.backend, Pinger: p})
+	}
+
+	return health.New(env.HealthCheckTimeout, l, checks...)
+}
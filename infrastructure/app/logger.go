@@ -0,0 +1,15 @@
+package app
+
+import (
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+// newLogger creates the logger of the config, a level or format it does not know fails the boot
+func newLogger(env *config.Env) (*logger.Logger, error) {
+	return logger.NewWithOptions(logger.Options{
+		Level:    env.LogLevel,
+		Format:   env.LogFormat,
+		Sampling: env.LogSampling,
+	})
+}
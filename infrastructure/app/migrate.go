@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/infrastructure/migrations"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+)
+
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+)
+
+// Migrate runs a migrate subcommand against the user database of the config, writing its result to w
+func Migrate(env *config.Env, command string, w io.Writer) error {
+	l, err := newLogger(env)
+	if err != nil {
+		return err
+	}
+
+	db := mysqlrepo.Connect(userDBDialector(env), false, l)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	m, err := migrations.New(sqlDB, db.Dialector.Name(), l)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command {
+	case MigrateUp:
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Fprintf(w, "applied  %04d %s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(w, "no pending migrations")
+		}
+		return err
+	case MigrateDown:
+		reverted, err := m.Down(ctx)
+		if reverted != nil {
+			fmt.Fprintf(w, "reverted %04d %s\n", reverted.Version, reverted.Name)
+		}
+		if err == nil && reverted == nil {
+			fmt.Fprintln(w, "no applied migrations")
+		}
+		return err
+	case MigrateStatus:
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied at " + st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d %-30s %s\n", st.Version, st.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, use %s, %s or %s", command, MigrateUp, MigrateDown, MigrateStatus)
+	}
+}
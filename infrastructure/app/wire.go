@@ -5,17 +5,16 @@ package app
 
 import (
 	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/infrastructure/lifecycle"
+	"github.com/rahul-aut-ind/service-user/infrastructure/metrics"
+	"github.com/rahul-aut-ind/service-user/infrastructure/rotation"
 	"github.com/rahul-aut-ind/service-user/infrastructure/routes"
+	"github.com/rahul-aut-ind/service-user/infrastructure/tracing"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/controllers"
 	usercontroller2 "github.com/rahul-aut-ind/service-user/interfaceadapters/controllers"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/middlewares"
-	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
-	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
-	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/requesthandler"
-	"github.com/rahul-aut-ind/service-user/internal/awsconfig"
 	"github.com/rahul-aut-ind/service-user/internal/config"
-	"github.com/rahul-aut-ind/service-user/pkg/logger"
 	"github.com/rahul-aut-ind/service-user/services/imageservice"
 	"github.com/rahul-aut-ind/service-user/services/userservice"
 
@@ -23,29 +22,40 @@ import (
 	"github.com/google/wire"
 )
 
-func New(e *gin.Engine) (*App, error) {
+func New(e *gin.Engine, env *config.Env) (*App, error) {
 	wire.Build(
-		logger.Wired,
+		newLogger,
 
-		config.Wired,
+		lifecycle.Wired,
 
-		awsconfig.Wired,
+		rotation.Wired,
+
+		newAWSConfig,
+
+		metrics.Wired,
+
+		tracing.Wired,
 
 		requesthandler.Wired,
 
 		middlewares.Wired,
 
-		caching.Wired,
-		wire.Bind(new(caching.CacheHandler), new(*caching.RedisClient)),
+		newCacheBackend,
+
+		newCache,
+		caching.NewReadThrough,
+
+		newRateLimiter,
+
+		newIdempotencyStore,
+
+		newUserDB,
 
-		mysqlrepo.Wired,
-		wire.Bind(new(mysqlrepo.DataHandler), new(*mysqlrepo.MysqlClient)),
+		newImageStorage,
 
-		s3repo.Wired,
-		wire.Bind(new(s3repo.S3Handler), new(*s3repo.S3Repo)),
+		newImageDB,
 
-		dynamorepo.Wired,
-		wire.Bind(new(dynamorepo.DataHandler), new(*dynamorepo.DynamoDBRepo)),
+		newHealthChecker,
 
 		userservice.Wired,
 		wire.Bind(new(userservice.UserService), new(*userservice.Service)),
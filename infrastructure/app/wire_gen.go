@@ -9,36 +9,50 @@ package app
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/infrastructure/lifecycle"
+	"github.com/rahul-aut-ind/service-user/infrastructure/metrics"
+	"github.com/rahul-aut-ind/service-user/infrastructure/rotation"
 	"github.com/rahul-aut-ind/service-user/infrastructure/routes"
+	"github.com/rahul-aut-ind/service-user/infrastructure/tracing"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/controllers"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/middlewares"
-	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
-	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
-	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/requesthandler"
-	"github.com/rahul-aut-ind/service-user/internal/awsconfig"
 	"github.com/rahul-aut-ind/service-user/internal/config"
-	"github.com/rahul-aut-ind/service-user/pkg/logger"
 	"github.com/rahul-aut-ind/service-user/services/imageservice"
 	"github.com/rahul-aut-ind/service-user/services/userservice"
 )
 
 // Injectors from wire.go:
 
-func New(e *gin.Engine) (*App, error) {
+func New(e *gin.Engine, env *config.Env) (*App, error) {
 	requestHandler := requesthandler.New(e)
-	env := config.NewEnv()
-	loggerLogger := logger.New()
-	redisClient := caching.New(env, loggerLogger)
-	mysqlClient := mysqlrepo.New(loggerLogger, env)
-	service := userservice.New(mysqlClient, loggerLogger)
-	awsConfig := awsconfig.NewAWSConfig(env)
-	dynamoDBRepo := dynamorepo.New(awsConfig, env, loggerLogger)
-	s3Repo := s3repo.New(loggerLogger, awsConfig, env)
-	imageserviceService := imageservice.New(dynamoDBRepo, s3Repo, loggerLogger)
-	controller := controllers.New(redisClient, service, imageserviceService, loggerLogger)
-	validator := middlewares.New(loggerLogger)
-	routesRoutes := routes.New(requestHandler, controller, validator)
-	app := newApp(routesRoutes, env, loggerLogger, e)
+	logger, err := newLogger(env)
+	if err != nil {
+		return nil, err
+	}
+	lifecycleLifecycle := lifecycle.New(logger)
+	appCacheBackend := newCacheBackend(logger, env, lifecycleLifecycle)
+	metricsMetrics := metrics.New()
+	tracingTracing := tracing.New(env, logger, lifecycleLifecycle)
+	cacheHandler := newCache(appCacheBackend, env, metricsMetrics, tracingTracing)
+	readThrough := caching.NewReadThrough(cacheHandler, env, logger)
+	rotationRotation := rotation.New(env, logger)
+	dataHandler := newUserDB(logger, env, lifecycleLifecycle, metricsMetrics, tracingTracing, rotationRotation)
+	service := userservice.New(dataHandler, logger)
+	awsConfig := newAWSConfig(env, rotationRotation)
+	dynamorepoDataHandler := newImageDB(logger, awsConfig, env, lifecycleLifecycle, metricsMetrics, tracingTracing)
+	s3Handler := newImageStorage(logger, awsConfig, env, lifecycleLifecycle, metricsMetrics, tracingTracing)
+	imageserviceService := imageservice.New(dynamorepoDataHandler, s3Handler, env, logger)
+	controller := controllers.New(readThrough, service, imageserviceService, logger)
+	checker := newHealthChecker(logger, env, dataHandler, cacheHandler, dynamorepoDataHandler, s3Handler)
+	healthController := controllers.NewHealthController(checker)
+	validator := middlewares.New(env, logger)
+	requestLogger := middlewares.NewRequestLogger(logger)
+	rateLimiter := newRateLimiter(appCacheBackend)
+	middlewaresRateLimiter := middlewares.NewRateLimiter(rateLimiter, env, logger)
+	idempotencyStore := newIdempotencyStore(appCacheBackend)
+	idempotency := middlewares.NewIdempotency(idempotencyStore, env, logger)
+	routesRoutes := routes.New(requestHandler, controller, healthController, validator, requestLogger, middlewaresRateLimiter, idempotency, metricsMetrics, tracingTracing, logger)
+	app := newApp(routesRoutes, env, logger, e, lifecycleLifecycle, rotationRotation)
 	return app, nil
 }
@@ -0,0 +1,44 @@
+package caching
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	// IdempotencyStore keeps the responses of requests sent with an Idempotency-Key
+	IdempotencyStore interface {
+		CacheHandler
+		// SetNX sets the key only if it is not set yet, reporting whether it did
+		SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	}
+)
+
+// IdempotencyKeyPrefix is the prefix of the stored responses in the cache
+const IdempotencyKeyPrefix = "idempotency:"
+
+// SetNX sets the key in redis if it is missing, atomically so concurrent instances set it once
+func (rc *RedisClient) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	ok, err := rc.redisClient.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		rc.log.Infof("error setting %s :: err %s", key, err)
+		return false, fmt.Errorf("err:: %s", err)
+	}
+	return ok, nil
+}
+
+// SetNX sets the key if it is missing or expired
+func (mc *MemoryCache) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	now := mc.now()
+	if e, ok := mc.entries[key]; ok && now.Before(e.expiresAt) {
+		return false, nil
+	}
+	mc.sweepEntries(now)
+	mc.entries[key] = memoryEntry{value: value, expiresAt: now.Add(ttl)}
+
+	return true, nil
+}
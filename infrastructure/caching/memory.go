@@ -0,0 +1,89 @@
+package caching
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	// MemoryCache is a CacheHandler within the instance, for running without redis
+	MemoryCache struct {
+		mu      sync.Mutex
+		entries map[string]memoryEntry
+		windows map[string]*windowCount
+		log     *logger.Logger
+		now     func() time.Time
+	}
+
+	memoryEntry struct {
+		value     string
+		expiresAt time.Time
+	}
+)
+
+// sweepSize is the number of entries or rate limit counters after which the expired ones are dropped
+const sweepSize = 10000
+
+func NewMemoryCache(l *logger.Logger) *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryEntry),
+		windows: make(map[string]*windowCount),
+		log:     l,
+		now:     time.Now,
+	}
+}
+
+// Ping always succeeds, the cache lives in the process
+func (mc *MemoryCache) Ping(_ context.Context) error {
+	return nil
+}
+
+func (mc *MemoryCache) Get(_ context.Context, key string) (string, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	e, ok := mc.entries[key]
+	if !ok || !mc.now().Before(e.expiresAt) {
+		delete(mc.entries, key)
+		mc.log.Debugf("%s %s", key, KeyDoesNotExist)
+		return "", fmt.Errorf("%s", KeyDoesNotExist)
+	}
+
+	return e.value, nil
+}
+
+func (mc *MemoryCache) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	now := mc.now()
+	mc.sweepEntries(now)
+	mc.entries[key] = memoryEntry{value: value, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+// sweepEntries drops the expired entries once there are sweepSize of them
+func (mc *MemoryCache) sweepEntries(now time.Time) {
+	if len(mc.entries) < sweepSize {
+		return
+	}
+	for k, e := range mc.entries {
+		if !now.Before(e.expiresAt) {
+			delete(mc.entries, k)
+		}
+	}
+}
+
+func (mc *MemoryCache) Delete(_ context.Context, key string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	delete(mc.entries, key)
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package caching
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type (
+	// RateLimiter counts the requests of a key in a sliding window
+	RateLimiter interface {
+		// Allow counts a request of the key if it is within limit requests per window
+		Allow(ctx context.Context, key string, limit int, window time.Duration) (Allowance, error)
+	}
+
+	// Allowance is the outcome of counting a request against a limit
+	Allowance struct {
+		// Allowed is false if the limit was reached, the request was not counted then
+		Allowed bool
+		// Remaining is the number of requests left in the window
+		Remaining int
+		// Reset is the time until the current window ends and the count goes down
+		Reset time.Duration
+	}
+
+	// windowCount counts the requests of a key in the current and the previous fixed window
+	windowCount struct {
+		start   time.Time
+		size    time.Duration
+		current int
+		prev    int
+	}
+)
+
+// RateLimitKeyPrefix is the prefix of the rate limit counters in the cache
+const RateLimitKeyPrefix = "ratelimit:"
+
+// slidingWindowScript weighs the count of the previous window by the share of it still within the sliding window
+// and adds the count of the current one, counting the request if the sum stays below the limit.
+// KEYS are the counters of the current and the previous window, ARGV the limit, the window and the time
+// elapsed in the current window, both in milliseconds.
+var slidingWindowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+local count = math.floor(previous * (window - elapsed) / window) + current
+if count >= limit then
+	return {0, count}
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, count + 1}
+`)
+
+// Allow counts the request in redis, atomically so concurrent instances share the limit
+func (rc *RedisClient) Allow(ctx context.Context, key string, limit int, window time.Duration) (Allowance, error) {
+	now := time.Now()
+	idx, elapsed := windowOf(now, window)
+	keys := []string{
+		RateLimitKeyPrefix + key + ":" + strconv.FormatInt(idx, 10),
+		RateLimitKeyPrefix + key + ":" + strconv.FormatInt(idx-1, 10),
+	}
+
+	res, err := slidingWindowScript.Run(ctx, rc.redisClient, keys, limit, window.Milliseconds(), elapsed.Milliseconds()).Slice()
+	if err != nil {
+		return Allowance{}, fmt.Errorf("err:: %s", err)
+	}
+	if len(res) != 2 {
+		return Allowance{}, fmt.Errorf("err:: unexpected rate limit result %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	count, _ := res[1].(int64)
+	return allowance(allowed == 1, int(count), limit, window-elapsed), nil
+}
+
+// Allow counts the request within the instance, each instance has a limit of its own
+func (mc *MemoryCache) Allow(_ context.Context, key string, limit int, window time.Duration) (Allowance, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	now := mc.now()
+	idx, elapsed := windowOf(now, window)
+	start := time.UnixMilli(idx * window.Milliseconds())
+
+	if len(mc.windows) >= sweepSize {
+		mc.sweepWindows(now)
+	}
+	w := mc.windows[key]
+	switch {
+	case w == nil || start.Sub(w.start) > window:
+		w = &windowCount{start: start, size: window}
+	case start.After(w.start):
+		// the window after the one counted, it becomes the previous one
+		w = &windowCount{start: start, size: window, prev: w.current}
+	}
+	mc.windows[key] = w
+
+	count := int(math.Floor(float64(w.prev)*float64(window-elapsed)/float64(window))) + w.current
+	if count >= limit {
+		return allowance(false, count, limit, window-elapsed), nil
+	}
+	w.current++
+	return allowance(true, count+1, limit, window-elapsed), nil
+}
+
+// sweepWindows drops the counters no longer adding to any count
+func (mc *MemoryCache) sweepWindows(now time.Time) {
+	for k, w := range mc.windows {
+		if now.Sub(w.start) > 2*w.size {
+			delete(mc.windows, k)
+		}
+	}
+}
+
+// windowOf returns the index of the fixed window holding t and the time elapsed within it
+func windowOf(t time.Time, window time.Duration) (int64, time.Duration) {
+	ms := t.UnixMilli()
+	size := window.Milliseconds()
+	return ms / size, time.Duration(ms%size) * time.Millisecond
+}
+
+func allowance(allowed bool, count, limit int, reset time.Duration) Allowance {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Allowance{Allowed: allowed, Remaining: remaining, Reset: reset}
+}
@@ -0,0 +1,46 @@
+package caching
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache_AllowSlidesTheWindow(t *testing.T) {
+	mc := NewMemoryCache(logger.New())
+	now := time.UnixMilli(0)
+	mc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		a, err := mc.Allow(ctx, "k", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, a.Allowed)
+		assert.Equal(t, 2-i, a.Remaining)
+	}
+	a, _ := mc.Allow(ctx, "k", 3, time.Minute)
+	assert.False(t, a.Allowed, "limit reached")
+	assert.Equal(t, time.Minute, a.Reset)
+
+	// a third into the next window two thirds of the previous count still weigh in
+	now = now.Add(80 * time.Second)
+	a, _ = mc.Allow(ctx, "k", 3, time.Minute)
+	assert.True(t, a.Allowed)
+	assert.Equal(t, 0, a.Remaining)
+	assert.Equal(t, 40*time.Second, a.Reset)
+	a, _ = mc.Allow(ctx, "k", 3, time.Minute)
+	assert.False(t, a.Allowed)
+
+	a, _ = mc.Allow(ctx, "other", 3, time.Minute)
+	assert.True(t, a.Allowed, "keys are counted apart")
+
+	// two windows later nothing counts anymore
+	now = now.Add(2 * time.Minute)
+	a, _ = mc.Allow(ctx, "k", 3, time.Minute)
+	assert.True(t, a.Allowed)
+	assert.Equal(t, 2, a.Remaining)
+}
@@ -0,0 +1,178 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+type (
+	// ReadThrough reads values through the cache, loading a missing value once for all concurrent readers.
+	// Values not found are cached for a short while, so unknown keys do not reach the database on every read.
+	// Values past their TTL are served stale for a while longer, while one reader refreshes them in the background.
+	ReadThrough struct {
+		cache       CacheHandler
+		group       singleflight.Group
+		ttl         time.Duration
+		notFoundTTL time.Duration
+		staleTTL    time.Duration
+		jitter      float64
+		log         *logger.Logger
+		now         func() time.Time
+	}
+
+	// Loader loads the value of a key missing in the cache, returning an error marked by NotFound if there is none
+	Loader func(ctx context.Context) (string, error)
+
+	// cachedValue is what is kept in the cache for a key, the value or the error it was not found with
+	cachedValue struct {
+		Value      string    `json:"value,omitempty"`
+		NotFound   bool      `json:"notFound,omitempty"`
+		Err        string    `json:"err,omitempty"`
+		FreshUntil time.Time `json:"freshUntil"`
+	}
+
+	notFoundError struct {
+		err error
+	}
+)
+
+// ErrNotFound is matched by the errors of values not found, loaded or cached
+var ErrNotFound = stderrors.New("not found")
+
+func NewReadThrough(cache CacheHandler, env *config.Env, l *logger.Logger) *ReadThrough {
+	return &ReadThrough{
+		cache:       cache,
+		ttl:         env.CacheTTL,
+		notFoundTTL: env.CacheNotFoundTTL,
+		staleTTL:    env.CacheStaleTTL,
+		jitter:      env.CacheTTLJitter,
+		log:         l,
+		now:         time.Now,
+	}
+}
+
+// NotFound marks err as the result of a Loader finding no value, keeping its message
+func NotFound(err error) error {
+	return &notFoundError{err: err}
+}
+
+// Get returns the value of key from the cache, loading it on a miss. A stale value is returned as is
+// and refreshed in the background.
+func (rt *ReadThrough) Get(ctx context.Context, key string, load Loader) (string, error) {
+	if cached, ok := rt.cached(ctx, key); ok {
+		if !rt.now().Before(cached.FreshUntil) {
+			rt.log.For(ctx).Debugf("serving stale %s, refreshing it", key)
+			// the refresh outlives the request, its result is not waited for
+			rt.group.DoChan(key, func() (interface{}, error) {
+				return rt.load(context.WithoutCancel(ctx), key, load)
+			})
+		}
+		return cached.result()
+	}
+
+	rt.log.For(ctx).Debugf("cache miss %s", key)
+	// the load is shared by the readers of the key, one of them leaving must not fail the others
+	v, err, _ := rt.group.Do(key, func() (interface{}, error) {
+		return rt.load(context.WithoutCancel(ctx), key, load)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(cachedValue).result()
+}
+
+// Set caches value for key, e.g. right after it was written to the database
+func (rt *ReadThrough) Set(ctx context.Context, key, value string) error {
+	return rt.store(ctx, key, cachedValue{Value: value}, rt.ttl)
+}
+
+// Delete drops key from the cache, the next Get loads it again
+func (rt *ReadThrough) Delete(ctx context.Context, key string) error {
+	return rt.cache.Delete(ctx, key)
+}
+
+func (rt *ReadThrough) cached(ctx context.Context, key string) (cachedValue, bool) {
+	data, err := rt.cache.Get(ctx, key)
+	if err != nil {
+		return cachedValue{}, false
+	}
+	var cached cachedValue
+	if err := json.Unmarshal([]byte(data), &cached); err != nil {
+		rt.log.For(ctx).Warnf("err reading cached %s, loading it again :: %s", key, err)
+		return cachedValue{}, false
+	}
+	return cached, true
+}
+
+// load loads the value of key and caches it, or caches that it was not found.
+// Other errors are returned and not cached.
+func (rt *ReadThrough) load(ctx context.Context, key string, load Loader) (cachedValue, error) {
+	value, err := load(ctx)
+	var nf *notFoundError
+	switch {
+	case err == nil:
+		cached := cachedValue{Value: value}
+		rt.logStoreErr(ctx, key, rt.store(ctx, key, cached, rt.ttl))
+		return cached, nil
+	case stderrors.As(err, &nf):
+		cached := cachedValue{NotFound: true, Err: err.Error()}
+		if rt.notFoundTTL > 0 {
+			rt.logStoreErr(ctx, key, rt.store(ctx, key, cached, rt.notFoundTTL))
+		}
+		return cached, nil
+	default:
+		return cachedValue{}, err
+	}
+}
+
+// store caches value fresh for the jittered ttl, so keys cached together do not expire together,
+// and keeps it stale for staleTTL after
+func (rt *ReadThrough) store(ctx context.Context, key string, value cachedValue, ttl time.Duration) error {
+	ttl = rt.jittered(ttl)
+	value.FreshUntil = rt.now().Add(ttl)
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return rt.cache.Set(ctx, key, string(data), ttl+rt.staleTTL)
+}
+
+func (rt *ReadThrough) logStoreErr(ctx context.Context, key string, err error) {
+	if err != nil {
+		rt.log.For(ctx).Warnf("err updating cache %s :: %s", key, err)
+	}
+}
+
+// jittered spreads ttl evenly by up to jitter of it in either direction
+func (rt *ReadThrough) jittered(ttl time.Duration) time.Duration {
+	if rt.jitter <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration((rand.Float64()*2-1)*rt.jitter*float64(ttl))
+}
+
+func (c cachedValue) result() (string, error) {
+	if c.NotFound {
+		return "", NotFound(stderrors.New(c.Err))
+	}
+	return c.Value, nil
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
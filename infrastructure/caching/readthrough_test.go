@@ -0,0 +1,148 @@
+package caching
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readThroughClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *readThroughClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *readThroughClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newReadThrough(jitter float64) (*ReadThrough, *readThroughClock) {
+	clock := &readThroughClock{now: time.Unix(0, 0)}
+	mc := NewMemoryCache(logger.New())
+	mc.now = clock.Now
+	env := &config.Env{CacheTTL: 30 * time.Second, CacheNotFoundTTL: 5 * time.Second, CacheStaleTTL: 30 * time.Second,
+		CacheTTLJitter: jitter}
+	rt := NewReadThrough(mc, env, logger.New())
+	rt.now = clock.Now
+	return rt, clock
+}
+
+func TestReadThrough_CoalescesConcurrentMisses(t *testing.T) {
+	rt, _ := newReadThrough(0)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "user", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := rt.Get(context.Background(), "user:1", load)
+			assert.NoError(t, err)
+			assert.Equal(t, "user", v)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	v, _ := rt.Get(context.Background(), "user:1", load)
+	assert.Equal(t, "user", v)
+	assert.Equal(t, int32(1), loads.Load(), "served from the cache")
+}
+
+func TestReadThrough_CachesNotFound(t *testing.T) {
+	rt, clock := newReadThrough(0)
+	loads := 0
+	load := func(context.Context) (string, error) {
+		loads++
+		return "", NotFound(stderrors.New("NoUserFound"))
+	}
+
+	_, err := rt.Get(context.Background(), "user:9", load)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = rt.Get(context.Background(), "user:9", load)
+
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "NoUserFound", err.Error(), "the message is kept")
+	assert.Equal(t, 1, loads)
+
+	clock.Add(time.Minute)
+	_, _ = rt.Get(context.Background(), "user:9", load)
+	assert.Equal(t, 2, loads, "loaded again once expired")
+}
+
+func TestReadThrough_DoesNotCacheErrors(t *testing.T) {
+	rt, _ := newReadThrough(0)
+	loads := 0
+	load := func(context.Context) (string, error) {
+		loads++
+		return "", stderrors.New("connection refused")
+	}
+
+	_, err := rt.Get(context.Background(), "user:1", load)
+	assert.EqualError(t, err, "connection refused")
+	assert.NotErrorIs(t, err, ErrNotFound)
+	_, _ = rt.Get(context.Background(), "user:1", load)
+	assert.Equal(t, 2, loads)
+}
+
+func TestReadThrough_ServesStaleWhileRevalidating(t *testing.T) {
+	rt, clock := newReadThrough(0)
+	require.NoError(t, rt.Set(context.Background(), "user:1", "old"))
+	refreshed := make(chan struct{})
+	load := func(ctx context.Context) (string, error) {
+		defer close(refreshed)
+		assert.NoError(t, ctx.Err(), "the refresh outlives the request")
+		return "new", nil
+	}
+
+	clock.Add(45 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	v, err := rt.Get(ctx, "user:1", load)
+	cancel()
+
+	require.NoError(t, err)
+	assert.Equal(t, "old", v, "stale value served right away")
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale value not refreshed")
+	}
+	require.Eventually(t, func() bool {
+		v, _ := rt.Get(context.Background(), "user:1", load)
+		return v == "new"
+	}, time.Second, time.Millisecond)
+}
+
+func TestReadThrough_JittersTTL(t *testing.T) {
+	rt, _ := newReadThrough(0.1)
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		ttl := rt.jittered(30 * time.Second)
+		assert.InDelta(t, float64(30*time.Second), float64(ttl), float64(3*time.Second))
+		seen[ttl] = true
+	}
+	assert.Greater(t, len(seen), 1)
+}
@@ -32,22 +32,35 @@ func New(env *config.Env, l *logger.Logger) *RedisClient {
 	ch := &RedisClient{
 		log: l,
 	}
-	ch.redisClient = ch.initRedis(env.RedisAddress)
+	ch.redisClient = ch.initRedis(env.RedisAddress, env.RedisTimeout)
 	return ch
 }
 
-func (rc *RedisClient) initRedis(addr string) *redis.Client {
+func (rc *RedisClient) initRedis(addr string, timeout time.Duration) *redis.Client {
 	redisClient := redis.NewClient(&redis.Options{
-		Addr: addr,
+		Addr:         addr,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
 	})
 
+	// redis being down only flips readiness, the client reconnects once it is back
 	if _, err := redisClient.Ping(context.TODO()).Result(); err != nil {
-		rc.log.Fatalf("could not connect to redis: %v", err)
+		rc.log.Errorf("could not connect to redis: %v", err)
+		return redisClient
 	}
 	rc.log.Debug("connected to redis...")
 	return redisClient
 }
 
+func (rc *RedisClient) Close() error {
+	return rc.redisClient.Close()
+}
+
+func (rc *RedisClient) Ping(ctx context.Context) error {
+	return rc.redisClient.Ping(ctx).Err()
+}
+
 func (rc *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	res, err := rc.redisClient.Get(ctx, key).Result()
 	if err == redis.Nil {
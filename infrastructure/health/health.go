@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	// Pinger is implemented by the dependencies the service needs to serve requests
+	Pinger interface {
+		Ping(ctx context.Context) error
+	}
+
+	// Check names a dependency checked for readiness by its role and the backend running it
+	Check struct {
+		Name    string
+		Backend string
+		Pinger  Pinger
+	}
+
+	// Checker pings all dependencies concurrently, each bounded by the timeout
+	Checker struct {
+		checks  []Check
+		timeout time.Duration
+		log     *logger.Logger
+	}
+
+	// Result is the outcome of one dependency check
+	Result struct {
+		Backend   string `json:"backend,omitempty"`
+		Status    string `json:"status"`
+		LatencyMs int64  `json:"latencyMs"`
+		Error     string `json:"error,omitempty"`
+	}
+
+	// Report is the readiness of the service with the result of every dependency
+	Report struct {
+		Status string            `json:"status"`
+		Checks map[string]Result `json:"checks"`
+	}
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// New creates a Checker for the dependencies, every check gets at most timeout to answer
+func New(timeout time.Duration, l *logger.Logger, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout, log: l}
+}
+
+// Ready pings every dependency concurrently. The report is unavailable if any check failed,
+// a check that does not answer in time fails without holding up the report.
+func (c *Checker) Ready(ctx context.Context) *Report {
+	report := &Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			res := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = res
+			if res.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check.Pinger.Ping(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("no answer within %s", c.timeout)
+	}
+
+	res := Result{Backend: check.Backend, Status: StatusOK, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		c.log.Warnf("readiness check %s failed :: %v", check.Name, err)
+		res.Status = StatusUnavailable
+		res.Error = err.Error()
+	}
+	return res
+}
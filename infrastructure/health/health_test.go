@@ -0,0 +1,56 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type pingFunc func(ctx context.Context) error
+
+func (f pingFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+var (
+	healthy = pingFunc(func(context.Context) error { return nil })
+	down    = pingFunc(func(context.Context) error { return errors.New("connection refused") })
+	// hanging ignores its context, like a client without timeouts
+	hanging = pingFunc(func(context.Context) error { time.Sleep(time.Minute); return nil })
+)
+
+func TestChecker_Ready_AllHealthy(t *testing.T) {
+	c := New(time.Second, logger.New(), Check{Name: "mysql", Pinger: healthy}, Check{Name: "redis", Pinger: healthy})
+
+	report := c.Ready(context.Background())
+
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusOK, report.Checks["mysql"].Status)
+	assert.Empty(t, report.Checks["redis"].Error)
+}
+
+func TestChecker_Ready_ReportsFailedDependency(t *testing.T) {
+	c := New(time.Second, logger.New(), Check{Name: "mysql", Pinger: healthy}, Check{Name: "redis", Pinger: down})
+
+	report := c.Ready(context.Background())
+
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["mysql"].Status)
+	assert.Equal(t, StatusUnavailable, report.Checks["redis"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+}
+
+func TestChecker_Ready_TimesOutHangingDependency(t *testing.T) {
+	c := New(50*time.Millisecond, logger.New(), Check{Name: "s3", Pinger: hanging}, Check{Name: "dynamodb", Pinger: healthy})
+
+	start := time.Now()
+	report := c.Ready(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Contains(t, report.Checks["s3"].Error, "no answer within")
+	assert.Equal(t, StatusOK, report.Checks["dynamodb"].Status)
+}
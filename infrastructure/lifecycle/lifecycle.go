@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	// Hook is a component taking part in the lifecycle, both funcs are optional
+	Hook struct {
+		Name    string
+		OnStart func(ctx context.Context) error
+		OnStop  func(ctx context.Context) error
+	}
+
+	// Lifecycle starts the hooks in the order they were appended and stops them in reverse,
+	// so a component is stopped before the ones it depends on
+	Lifecycle struct {
+		mu      sync.Mutex
+		hooks   []Hook
+		started int
+		log     *logger.Logger
+	}
+)
+
+func New(l *logger.Logger) *Lifecycle {
+	return &Lifecycle{log: l}
+}
+
+// Append registers a hook, it must be called before Start
+func (lc *Lifecycle) Append(h Hook) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.hooks = append(lc.hooks, h)
+}
+
+// Start runs the start hooks in order. If one fails, the hooks started before it are stopped again.
+func (lc *Lifecycle) Start(ctx context.Context) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	for _, h := range lc.hooks[lc.started:] {
+		if h.OnStart != nil {
+			lc.log.Debugf("starting %s", h.Name)
+			if err := h.OnStart(ctx); err != nil {
+				startErr := fmt.Errorf("could not start %s :: %w", h.Name, err)
+				if stopErr := lc.stop(ctx); stopErr != nil {
+					return stderrors.Join(startErr, stopErr)
+				}
+				return startErr
+			}
+		}
+		lc.started++
+	}
+
+	return nil
+}
+
+// Stop runs the stop hooks of the started components in reverse order. Every hook runs even if
+// one before it failed, the errors are joined. Hooks are expected to give up once ctx is done.
+func (lc *Lifecycle) Stop(ctx context.Context) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.stop(ctx)
+}
+
+func (lc *Lifecycle) stop(ctx context.Context) error {
+	var errs []error
+	for ; lc.started > 0; lc.started-- {
+		h := lc.hooks[lc.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+		lc.log.Debugf("stopping %s", h.Name)
+		if err := h.OnStop(ctx); err != nil {
+			lc.log.Errorf("could not stop %s :: %v", h.Name, err)
+			errs = append(errs, fmt.Errorf("could not stop %s :: %w", h.Name, err))
+		}
+	}
+	return stderrors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder appends a hook that records its start and stop, failing with the given errors
+func recorder(lc *Lifecycle, calls *[]string, name string, startErr, stopErr error) {
+	lc.Append(Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return stopErr
+		},
+	})
+}
+
+func TestLifecycle_StartsInOrderAndStopsInReverse(t *testing.T) {
+	var calls []string
+	lc := New(logger.New())
+	recorder(lc, &calls, "db", nil, nil)
+	recorder(lc, &calls, "cache", nil, nil)
+	recorder(lc, &calls, "server", nil, nil)
+
+	assert.NoError(t, lc.Start(context.Background()))
+	assert.NoError(t, lc.Stop(context.Background()))
+
+	assert.Equal(t, []string{"start db", "start cache", "start server", "stop server", "stop cache", "stop db"}, calls)
+}
+
+func TestLifecycle_StopsStartedHooksWhenStartFails(t *testing.T) {
+	var calls []string
+	lc := New(logger.New())
+	recorder(lc, &calls, "db", nil, nil)
+	recorder(lc, &calls, "cache", errors.New("connection refused"), nil)
+	recorder(lc, &calls, "server", nil, nil)
+
+	err := lc.Start(context.Background())
+
+	assert.ErrorContains(t, err, "could not start cache")
+	assert.Equal(t, []string{"start db", "start cache", "stop db"}, calls)
+}
+
+func TestLifecycle_RunsEveryStopHookAndJoinsErrors(t *testing.T) {
+	var calls []string
+	lc := New(logger.New())
+	recorder(lc, &calls, "db", nil, errors.New("db busy"))
+	recorder(lc, &calls, "cache", nil, errors.New("cache busy"))
+
+	assert.NoError(t, lc.Start(context.Background()))
+	err := lc.Stop(context.Background())
+
+	assert.ErrorContains(t, err, "db busy")
+	assert.ErrorContains(t, err, "cache busy")
+	assert.Equal(t, []string{"start db", "start cache", "stop cache", "stop db"}, calls)
+
+	// a second stop has nothing left to stop
+	assert.NoError(t, lc.Stop(context.Background()))
+}
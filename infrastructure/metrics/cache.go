@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+)
+
+var _ caching.CacheHandler = (*Cache)(nil)
+
+// Cache counts the hits and misses of the user cache
+type Cache struct {
+	next    caching.CacheHandler
+	backend string
+	m       *Metrics
+}
+
+func NewCache(m *Metrics, backend string, next caching.CacheHandler) *Cache {
+	return &Cache{next: next, backend: backend, m: m}
+}
+
+func (c *Cache) Ping(ctx context.Context) error {
+	return ping(ctx, c.next)
+}
+
+func (c *Cache) Get(ctx context.Context, key string) (string, error) {
+	value, err := c.next.Get(ctx, key)
+	result := CacheHit
+	if err != nil {
+		result = CacheMiss
+	}
+	c.m.cacheLookups.WithLabelValues(c.backend, result).Inc()
+	return value, err
+}
+
+func (c *Cache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.next.Set(ctx, key, value, ttl)
+}
+
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	return c.next.Delete(ctx, key)
+}
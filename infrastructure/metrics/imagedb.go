@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+)
+
+var _ dynamorepo.DataHandler = (*ImageDB)(nil)
+
+// ImageDB times the calls to the image database and counts the failed ones
+type ImageDB struct {
+	next    dynamorepo.DataHandler
+	backend string
+	m       *Metrics
+}
+
+func NewImageDB(m *Metrics, backend string, next dynamorepo.DataHandler) *ImageDB {
+	return &ImageDB{next: next, backend: backend, m: m}
+}
+
+func (d *ImageDB) Ping(ctx context.Context) error {
+	return ping(ctx, d.next)
+}
+
+func (d *ImageDB) AddImage(ctx context.Context, p *models.UserImage) (err error) {
+	defer d.observe("AddImage", time.Now(), &err)
+	return d.next.AddImage(ctx, p)
+}
+
+func (d *ImageDB) GetAllImagesPaginated(ctx context.Context, req models.PaginatedInput) (_ *models.UserImageResult, err error) {
+	defer d.observe("GetAllImagesPaginated", time.Now(), &err)
+	return d.next.GetAllImagesPaginated(ctx, req)
+}
+
+func (d *ImageDB) GetImage(ctx context.Context, uID, imgID string) (_ *models.UserImage, err error) {
+	defer d.observe("GetImage", time.Now(), &err)
+	return d.next.GetImage(ctx, uID, imgID)
+}
+
+func (d *ImageDB) DeleteImage(ctx context.Context, uID, imgID string) (err error) {
+	defer d.observe("DeleteImage", time.Now(), &err)
+	return d.next.DeleteImage(ctx, uID, imgID)
+}
+
+func (d *ImageDB) DeleteAllImages(ctx context.Context, uID string) (err error) {
+	defer d.observe("DeleteAllImages", time.Now(), &err)
+	return d.next.DeleteAllImages(ctx, uID)
+}
+
+func (d *ImageDB) SetImageTags(ctx context.Context, uID, imgID string, tags []string) (err error) {
+	defer d.observe("SetImageTags", time.Now(), &err)
+	return d.next.SetImageTags(ctx, uID, imgID, tags)
+}
+
+func (d *ImageDB) AddImageToAlbum(ctx context.Context, uID, imgID, albumID string) (err error) {
+	defer d.observe("AddImageToAlbum", time.Now(), &err)
+	return d.next.AddImageToAlbum(ctx, uID, imgID, albumID)
+}
+
+func (d *ImageDB) RemoveImageFromAlbum(ctx context.Context, uID, imgID, albumID string) (err error) {
+	defer d.observe("RemoveImageFromAlbum", time.Now(), &err)
+	return d.next.RemoveImageFromAlbum(ctx, uID, imgID, albumID)
+}
+
+// IterateImages is timed without fn, only the time spent querying the pages is recorded
+func (d *ImageDB) IterateImages(ctx context.Context, uID string, from, to *time.Time, fn func(ui *models.UserImage) error) (err error) {
+	var inFn time.Duration
+	start := time.Now()
+	defer func() { d.m.observe(d.backend, "IterateImages", start.Add(inFn), err) }()
+
+	return d.next.IterateImages(ctx, uID, from, to, func(ui *models.UserImage) error {
+		fnStart := time.Now()
+		defer func() { inFn += time.Since(fnStart) }()
+		return fn(ui)
+	})
+}
+
+func (d *ImageDB) CreateAlbum(ctx context.Context, a *models.Album) (err error) {
+	defer d.observe("CreateAlbum", time.Now(), &err)
+	return d.next.CreateAlbum(ctx, a)
+}
+
+func (d *ImageDB) GetAlbum(ctx context.Context, uID, albumID string) (_ *models.Album, err error) {
+	defer d.observe("GetAlbum", time.Now(), &err)
+	return d.next.GetAlbum(ctx, uID, albumID)
+}
+
+func (d *ImageDB) ListAlbums(ctx context.Context, uID string) (_ []models.Album, err error) {
+	defer d.observe("ListAlbums", time.Now(), &err)
+	return d.next.ListAlbums(ctx, uID)
+}
+
+func (d *ImageDB) RenameAlbum(ctx context.Context, uID, albumID, name string) (_ *models.Album, err error) {
+	defer d.observe("RenameAlbum", time.Now(), &err)
+	return d.next.RenameAlbum(ctx, uID, albumID, name)
+}
+
+func (d *ImageDB) DeleteAlbum(ctx context.Context, uID, albumID string) (err error) {
+	defer d.observe("DeleteAlbum", time.Now(), &err)
+	return d.next.DeleteAlbum(ctx, uID, albumID)
+}
+
+func (d *ImageDB) GetUsage(ctx context.Context, uID string) (_ *models.Usage, err error) {
+	defer d.observe("GetUsage", time.Now(), &err)
+	return d.next.GetUsage(ctx, uID)
+}
+
+func (d *ImageDB) ReserveUsage(ctx context.Context, uID string, size int64, quota models.Quota) (err error) {
+	defer d.observe("ReserveUsage", time.Now(), &err)
+	return d.next.ReserveUsage(ctx, uID, size, quota)
+}
+
+func (d *ImageDB) ReleaseUsage(ctx context.Context, uID string, size int64) (err error) {
+	defer d.observe("ReleaseUsage", time.Now(), &err)
+	return d.next.ReleaseUsage(ctx, uID, size)
+}
+
+func (d *ImageDB) CreateShare(ctx context.Context, sh *models.Share) (err error) {
+	defer d.observe("CreateShare", time.Now(), &err)
+	return d.next.CreateShare(ctx, sh)
+}
+
+func (d *ImageDB) GetShare(ctx context.Context, shareID string) (_ *models.Share, err error) {
+	defer d.observe("GetShare", time.Now(), &err)
+	return d.next.GetShare(ctx, shareID)
+}
+
+func (d *ImageDB) ListShares(ctx context.Context, ownerID, imgID string) (_ []models.Share, err error) {
+	defer d.observe("ListShares", time.Now(), &err)
+	return d.next.ListShares(ctx, ownerID, imgID)
+}
+
+func (d *ImageDB) DeleteShare(ctx context.Context, ownerID, shareID string) (err error) {
+	defer d.observe("DeleteShare", time.Now(), &err)
+	return d.next.DeleteShare(ctx, ownerID, shareID)
+}
+
+func (d *ImageDB) observe(operation string, start time.Time, err *error) {
+	d.m.observe(d.backend, operation, start, *err)
+}
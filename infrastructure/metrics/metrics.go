@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+)
+
+type (
+	// Metrics holds the collectors of the service in a registry of its own
+	Metrics struct {
+		registry        *prometheus.Registry
+		requests        *prometheus.CounterVec
+		requestDuration *prometheus.HistogramVec
+		cacheLookups    *prometheus.CounterVec
+		callDuration    *prometheus.HistogramVec
+		callErrors      *prometheus.CounterVec
+		uploadSize      *prometheus.HistogramVec
+	}
+
+	// pinger is the health.Pinger of the decorated backends, forwarded by the decorators
+	pinger interface {
+		Ping(ctx context.Context) error
+	}
+)
+
+const (
+	namespace = "service_user"
+
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+
+	// unmatchedRoute labels requests no route was found for, keeping the label values bounded
+	unmatchedRoute = "unmatched"
+)
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route template and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "User cache lookups by result, hit or miss.",
+		}, []string{"backend", "result"}),
+		callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "backend_call_duration_seconds",
+			Help:      "Latency of the calls to the user database, the image database and the image storage.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"backend", "operation"}),
+		callErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "backend_call_errors_total",
+			Help:      "Failed calls to the user database, the image database and the image storage.",
+		}, []string{"backend", "operation"}),
+		uploadSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "image_upload_size_bytes",
+			Help:      "Size of the uploaded images.",
+			// 16KiB up to 64MiB
+			Buckets: prometheus.ExponentialBuckets(16<<10, 4, 7),
+		}, []string{"backend"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.cacheLookups,
+		m.callDuration,
+		m.callErrors,
+		m.uploadSize,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts and times requests. Routes are labeled by their template, /users/:id rather
+// than /users/1, so the number of series does not grow with the ids.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.requests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.requestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// observe records the latency of a backend call started at start, and counts it as failed
+// if it returned an error that is not the caller's fault
+func (m *Metrics) observe(backend, operation string, start time.Time, err error) {
+	m.callDuration.WithLabelValues(backend, operation).Observe(time.Since(start).Seconds())
+	if isFailure(err) {
+		m.callErrors.WithLabelValues(backend, operation).Inc()
+	}
+}
+
+// isFailure tells errors of the backend apart from domain errors like a missing record,
+// which are answered with a 4xx and say nothing about the backend
+func isFailure(err error) bool {
+	return err != nil && !errors.IsClientError(err)
+}
+
+// ping forwards a readiness check to the decorated backend, a backend that can not be pinged is taken as up
+func ping(ctx context.Context, next any) error {
+	if p, ok := next.(pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
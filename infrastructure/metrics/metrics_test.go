@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/fsrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findFunc is a user database answering FindRecord with f, the other methods are not used
+type findFunc struct {
+	mysqlrepo.DataHandler
+	f func(id string) (*models.User, error)
+}
+
+func (ff findFunc) FindRecord(_ context.Context, id string) (*models.User, error) {
+	return ff.f(id)
+}
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	e := gin.New()
+	e.Use(m.Middleware())
+	e.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/users/1", "/users/2", "/nothing-here"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, "/users/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.requestDuration))
+}
+
+func TestHandler_ServesMetrics(t *testing.T) {
+	m := New()
+	m.cacheLookups.WithLabelValues(config.CacheMemory, CacheHit).Inc()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, config.MetricsPath, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `service_user_cache_lookups_total{backend="memory",result="hit"} 1`)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
+
+func TestCache_CountsHitsAndMisses(t *testing.T) {
+	m := New()
+	cache := NewCache(m, config.CacheMemory, caching.NewMemoryCache(logger.New()))
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "1", "user", caching.DefaultTTL))
+	_, err := cache.Get(ctx, "1")
+	require.NoError(t, err)
+	_, err = cache.Get(ctx, "2")
+	require.Error(t, err)
+	_, _ = cache.Get(ctx, "3")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues(config.CacheMemory, CacheHit)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues(config.CacheMemory, CacheMiss)))
+}
+
+func TestUserDB_CountsOnlyBackendFailures(t *testing.T) {
+	m := New()
+	db := NewUserDB(m, config.DBDriverMySQL, findFunc{f: func(id string) (*models.User, error) {
+		switch id {
+		case "missing":
+			return nil, errors.New(errors.ErrCodeNoUser, fmt.Errorf("no user with id %s", id))
+		case "gone":
+			return nil, fmt.Errorf("err :: %v", errors.ErrCodeNoUser)
+		case "down":
+			return nil, stderrors.New("connection refused")
+		default:
+			return &models.User{Name: "found"}, nil
+		}
+	}})
+
+	for _, id := range []string{"1", "missing", "gone", "down"} {
+		_, _ = db.FindRecord(context.Background(), id)
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.callErrors.WithLabelValues(config.DBDriverMySQL, "FindRecord")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.callDuration))
+	assert.Same(t, db, db.Primary(), "a database without replicas is its own primary")
+}
+
+func TestImageStorage_RecordsUploadSize(t *testing.T) {
+	m := New()
+	env := &config.Env{LocalStorageDir: t.TempDir()}
+	storage := NewImageStorage(m, config.ImageStorageFS, fsrepo.New(logger.New(), env))
+
+	img := []byte(strings.Repeat("x", 20<<10))
+	_, err := storage.Save(context.Background(), "1", uuid.New(), ".jpg", &img)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.uploadSize))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.callErrors.WithLabelValues(config.ImageStorageFS, "Save")))
+}
+
+func TestImageStorage_OpenSignedOnlyForLocalFiles(t *testing.T) {
+	var s3 s3repo.S3Handler
+	storage := NewImageStorage(New(), config.ImageStorageS3, s3)
+
+	_, err := storage.OpenSigned(context.Background(), "key", "0", "signature")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/fsrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
+)
+
+var (
+	_ s3repo.S3Handler        = (*ImageStorage)(nil)
+	_ fsrepo.SignedFileOpener = (*ImageStorage)(nil)
+)
+
+// ImageStorage times the calls to the image storage, counts the failed ones and records the upload sizes
+type ImageStorage struct {
+	next    s3repo.S3Handler
+	backend string
+	m       *Metrics
+}
+
+func NewImageStorage(m *Metrics, backend string, next s3repo.S3Handler) *ImageStorage {
+	return &ImageStorage{next: next, backend: backend, m: m}
+}
+
+func (s *ImageStorage) Ping(ctx context.Context) error {
+	return ping(ctx, s.next)
+}
+
+func (s *ImageStorage) Save(ctx context.Context, uID string, imageID uuid.UUID, ext string, f *[]byte) (_ string, err error) {
+	s.m.uploadSize.WithLabelValues(s.backend).Observe(float64(len(*f)))
+	defer s.observe("Save", time.Now(), &err)
+	return s.next.Save(ctx, uID, imageID, ext, f)
+}
+
+func (s *ImageStorage) Delete(ctx context.Context, uID string, imageID string) (err error) {
+	defer s.observe("Delete", time.Now(), &err)
+	return s.next.Delete(ctx, uID, imageID)
+}
+
+// DeleteAll counts as failed if any file was left behind, not only if listing the files failed
+func (s *ImageStorage) DeleteAll(ctx context.Context, uID string) (*s3repo.DeleteResult, error) {
+	start := time.Now()
+	result, err := s.next.DeleteAll(ctx, uID)
+	failed := err
+	if failed == nil && result != nil {
+		failed = result.Err()
+	}
+	s.m.observe(s.backend, "DeleteAll", start, failed)
+	return result, err
+}
+
+func (s *ImageStorage) GetPresignedURL(ctx context.Context, key string, expiry time.Duration) (_ string, err error) {
+	defer s.observe("GetPresignedURL", time.Now(), &err)
+	return s.next.GetPresignedURL(ctx, key, expiry)
+}
+
+// Get is timed until the object is opened, reading it is up to the caller
+func (s *ImageStorage) Get(ctx context.Context, key string) (_ io.ReadCloser, err error) {
+	defer s.observe("Get", time.Now(), &err)
+	return s.next.Get(ctx, key)
+}
+
+// OpenSigned serves the signed local urls if the decorated storage is the local filesystem
+func (s *ImageStorage) OpenSigned(ctx context.Context, key, expires, signature string) (_ io.ReadCloser, err error) {
+	opener, ok := s.next.(fsrepo.SignedFileOpener)
+	if !ok {
+		return nil, fmt.Errorf("%s storage does not serve signed local urls", s.backend)
+	}
+	defer s.observe("OpenSigned", time.Now(), &err)
+	return opener.OpenSigned(ctx, key, expires, signature)
+}
+
+func (s *ImageStorage) observe(operation string, start time.Time, err *error) {
+	s.m.observe(s.backend, operation, start, *err)
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+)
+
+var (
+	_ mysqlrepo.DataHandler   = (*UserDB)(nil)
+	_ mysqlrepo.PrimaryPinner = (*UserDB)(nil)
+)
+
+// UserDB times the calls to the user database and counts the failed ones
+type UserDB struct {
+	next    mysqlrepo.DataHandler
+	backend string
+	m       *Metrics
+}
+
+func NewUserDB(m *Metrics, backend string, next mysqlrepo.DataHandler) *UserDB {
+	return &UserDB{next: next, backend: backend, m: m}
+}
+
+func (d *UserDB) Ping(ctx context.Context) error {
+	return ping(ctx, d.next)
+}
+
+// Primary keeps reads pinned to the primary measured
+func (d *UserDB) Primary() mysqlrepo.DataHandler {
+	if p, ok := d.next.(mysqlrepo.PrimaryPinner); ok {
+		return NewUserDB(d.m, d.backend, p.Primary())
+	}
+	return d
+}
+
+func (d *UserDB) ListRecords(ctx context.Context) (_ []models.User, err error) {
+	defer d.observe("ListRecords", time.Now(), &err)
+	return d.next.ListRecords(ctx)
+}
+
+func (d *UserDB) FindRecord(ctx context.Context, id string) (_ *models.User, err error) {
+	defer d.observe("FindRecord", time.Now(), &err)
+	return d.next.FindRecord(ctx, id)
+}
+
+func (d *UserDB) CreateRecord(ctx context.Context, u *models.User) (_ *models.User, err error) {
+	defer d.observe("CreateRecord", time.Now(), &err)
+	return d.next.CreateRecord(ctx, u)
+}
+
+func (d *UserDB) UpdateRecord(ctx context.Context, u *models.User) (_ *models.User, err error) {
+	defer d.observe("UpdateRecord", time.Now(), &err)
+	return d.next.UpdateRecord(ctx, u)
+}
+
+func (d *UserDB) DeleteRecord(ctx context.Context, u *models.User) (_ *models.User, err error) {
+	defer d.observe("DeleteRecord", time.Now(), &err)
+	return d.next.DeleteRecord(ctx, u)
+}
+
+func (d *UserDB) observe(operation string, start time.Time, err *error) {
+	d.m.observe(d.backend, operation, start, *err)
+}
@@ -1,10 +1,12 @@
 //go:build wireinject
 // +build wireinject
 
-package config
+package metrics
 
-import "github.com/google/wire"
+import (
+	"github.com/google/wire"
+)
 
 var Wired = wire.NewSet(
-	NewEnv,
+	New,
 )
@@ -0,0 +1,342 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	// Migration is one numbered schema change with the sql to apply and to revert it
+	Migration struct {
+		Version int64
+		Name    string
+		Up      string
+		Down    string
+	}
+
+	// Status tells if a migration is applied, AppliedAt is nil for a pending one
+	Status struct {
+		Version   int64
+		Name      string
+		AppliedAt *time.Time
+	}
+
+	// Migrator applies the migrations of one dialect, tracking them in the schema_migrations table
+	Migrator struct {
+		db         *sql.DB
+		dialect    string
+		migrations []Migration
+		log        *logger.Logger
+	}
+)
+
+const (
+	// lockName is held while migrating, so pods starting at the same time don't race
+	lockName = "service-user-migrations"
+	// postgresLockKey is the advisory lock key of lockName
+	postgresLockKey = 4242001
+	lockTimeout     = 60 * time.Second
+)
+
+// files holds the migrations as <dialect>/<version>_<name>.<up|down>.sql
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// New creates a Migrator for the gorm dialect name, i.e. mysql, postgres or sqlite
+func New(db *sql.DB, dialect string, l *logger.Logger) (*Migrator, error) {
+	migrations, err := load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations, log: l}, nil
+}
+
+// Up applies all pending migrations in order and returns the applied ones
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			m.log.Infof("applying migration %04d %s", mig.Version, mig.Name)
+			if err := m.run(ctx, conn, mig.Up, m.bind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+				mig.Version, mig.Name, time.Now().UTC()); err != nil {
+				return fmt.Errorf("migration %04d %s failed :: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the latest applied migration, it returns nil if none is applied
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			m.log.Infof("reverting migration %04d %s", mig.Version, mig.Name)
+			if err := m.run(ctx, conn, mig.Down, m.bind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version); err != nil {
+				return fmt.Errorf("reverting migration %04d %s failed :: %w", mig.Version, mig.Name, err)
+			}
+			reverted = &mig
+			return nil
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration in order, with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.createTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	done, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if appliedAt, ok := done[mig.Version]; ok {
+			st.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, st)
+	}
+
+	return statuses, nil
+}
+
+// run executes the statements of a migration and records it in one transaction.
+// MySQL commits DDL implicitly, so there a failed migration may be left half applied.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// a no-op once committed
+		_ = tx.Rollback()
+	}()
+
+	for _, stmt := range statements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// locked runs fn on one connection holding the migration lock of the database
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	unlock, err := m.lock(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("could not acquire migration lock :: %w", err)
+	}
+	defer unlock()
+
+	if err := m.createTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// lock takes a session lock, released by the returned func. SQLite is only used by a single
+// process in standalone mode and serializes writers itself, so it takes no lock.
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) (func(), error) {
+	switch m.dialect {
+	case "mysql":
+		var got sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&got)
+		if err != nil {
+			return nil, err
+		}
+		if got.Int64 != 1 {
+			return nil, fmt.Errorf("timed out waiting for lock %s", lockName)
+		}
+		return func() { m.unlock(conn, "SELECT RELEASE_LOCK(?)", lockName) }, nil
+	case "postgres":
+		lockCtx, cancel := context.WithTimeout(ctx, lockTimeout)
+		defer cancel()
+		if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", postgresLockKey); err != nil {
+			return nil, err
+		}
+		return func() { m.unlock(conn, "SELECT pg_advisory_unlock($1)", postgresLockKey) }, nil
+	default:
+		return func() {}, nil
+	}
+}
+
+func (m *Migrator) unlock(conn *sql.Conn, query string, arg any) {
+	if _, err := conn.ExecContext(context.Background(), query, arg); err != nil {
+		m.log.Errorf("could not release migration lock :: %v", err)
+	}
+}
+
+func (m *Migrator) createTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`)
+	return err
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = appliedAt
+	}
+
+	return done, rows.Err()
+}
+
+// bind rewrites the ? placeholders to $n for postgres
+func (m *Migrator) bind(query string) string {
+	if m.dialect != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// load reads the migrations of the dialect ordered by version, every up needs a down
+func load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %s", dialect)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(e.Name(), ".sql"), ".")
+		versionPart, name, found := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if !ok || !found || err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+
+		content, err := files.ReadFile(path.Join(dialect, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, exists := byVersion[version]
+		if !exists {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		}
+		switch direction {
+		case "up":
+			mig.Up = string(content)
+		case "down":
+			mig.Down = string(content)
+		default:
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %04d %s needs an up and a down file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// statements splits a script at the semicolons ending a line, dropping comments and empty statements
+func statements(script string) []string {
+	var stmts []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+
+	return stmts
+}
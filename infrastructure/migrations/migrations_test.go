@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldLoadMigrationsOfEveryDialect(t *testing.T) {
+	for _, dialect := range []string{"mysql", "postgres", "sqlite"} {
+		migrations, err := load(dialect)
+		require.NoError(t, err, dialect)
+		require.NotEmpty(t, migrations, dialect)
+		for i, mig := range migrations {
+			assert.Equal(t, int64(i+1), mig.Version, dialect)
+			assert.NotEmpty(t, mig.Up, dialect)
+			assert.NotEmpty(t, mig.Down, dialect)
+		}
+	}
+}
+
+func TestShouldApplyAndRevertMigrations(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "users.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := New(db, "sqlite", logger.New())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(m.migrations))
+
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	_, err = db.Exec("INSERT INTO users (name, email, created_at, updated_at) VALUES ('Test', 'test@example.com', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)")
+	require.NoError(t, err)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	for _, st := range statuses {
+		assert.NotNil(t, st.AppliedAt)
+	}
+
+	for range m.migrations {
+		reverted, err := m.Down(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, reverted)
+	}
+	reverted, err := m.Down(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, reverted)
+
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	for _, st := range statuses {
+		assert.Nil(t, st.AppliedAt)
+	}
+	_, err = db.Exec("SELECT 1 FROM users")
+	assert.Error(t, err)
+}
+
+func TestShouldSplitStatements(t *testing.T) {
+	script := "-- comment\nCREATE TABLE a (\n  id INT\n);\n\nCREATE INDEX i ON a (id);\n"
+	assert.Equal(t, []string{"CREATE TABLE a (\n  id INT\n)", "CREATE INDEX i ON a (id)"}, statements(script))
+}
+
+func TestShouldBindPostgresPlaceholders(t *testing.T) {
+	m := &Migrator{dialect: "postgres"}
+	assert.Equal(t, "VALUES ($1, $2)", m.bind("VALUES (?, ?)"))
+	m.dialect = "mysql"
+	assert.Equal(t, "VALUES (?, ?)", m.bind("VALUES (?, ?)"))
+}
@@ -0,0 +1,14 @@
+-- IF NOT EXISTS adopts tables created by gorm's AutoMigrate before versioned migrations
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    name LONGTEXT,
+    email VARCHAR(191),
+    address LONGTEXT,
+    age BIGINT,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_email UNIQUE (email),
+    INDEX idx_users_deleted_at (deleted_at)
+);
@@ -0,0 +1,13 @@
+-- IF NOT EXISTS adopts tables created by gorm's AutoMigrate before versioned migrations
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    name TEXT,
+    email TEXT,
+    address TEXT,
+    age BIGINT,
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
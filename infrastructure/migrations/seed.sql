@@ -0,0 +1,13 @@
+# Seed data, populating the users table with 10 users. Create the table first with `service-user migrate up`
+INSERT INTO users (name, email, address, age, created_at, updated_at) VALUES
+  ('User1', 'user1@example.com', 'Main Street 1', 21, NOW(), NOW()),
+  ('User2', 'user2@example.com', 'Main Street 2', 22, NOW(), NOW()),
+  ('User3', 'user3@example.com', 'Main Street 3', 23, NOW(), NOW()),
+  ('User4', 'user4@example.com', 'Main Street 4', 24, NOW(), NOW()),
+  ('User5', 'user5@example.com', 'Main Street 5', 25, NOW(), NOW()),
+  ('User6', 'user6@example.com', 'Main Street 6', 26, NOW(), NOW()),
+  ('User7', 'user7@example.com', 'Main Street 7', 27, NOW(), NOW()),
+  ('User8', 'user8@example.com', 'Main Street 8', 28, NOW(), NOW()),
+  ('User9', 'user9@example.com', 'Main Street 9', 29, NOW(), NOW()),
+  ('User10', 'user10@example.com', 'Main Street 10', 30, NOW(), NOW())
+;
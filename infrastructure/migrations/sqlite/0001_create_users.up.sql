@@ -0,0 +1,13 @@
+-- IF NOT EXISTS adopts tables created by gorm's AutoMigrate before versioned migrations
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    name TEXT,
+    email TEXT,
+    address TEXT,
+    age INTEGER,
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
@@ -0,0 +1,109 @@
+// Package rotation applies rotated secrets to the running service
+package rotation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/infrastructure/lifecycle"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	// Rotation reads the secret references of the config again every SecretsRefreshInterval and hands the
+	// config with the rotated secrets to the subscribers using them, which rebuild their clients
+	Rotation struct {
+		env *config.Env
+		log *logger.Logger
+
+		mu   sync.Mutex
+		subs []*subscription
+		stop chan struct{}
+		done chan struct{}
+	}
+
+	subscription struct {
+		name    string
+		changed func(applied, next *config.Env) bool
+		apply   func(ctx context.Context, next *config.Env) error
+		// applied is the config the subscriber last applied
+		applied *config.Env
+	}
+)
+
+// New creates a Rotation of the secrets of env, refreshing them while its Hook runs
+func New(env *config.Env, l *logger.Logger) *Rotation {
+	return &Rotation{env: env, log: l}
+}
+
+// Hook refreshes the secrets every SecretsRefreshInterval from the start of the app until it stops.
+// It is to be appended after the subscribers, so no refresh runs while they are stopped.
+func (r *Rotation) Hook() lifecycle.Hook {
+	return lifecycle.Hook{Name: "secret rotation", OnStart: r.start, OnStop: r.shutdown}
+}
+
+// Subscribe calls apply with the refreshed config whenever changed reports that a secret used by the subscriber
+// differs from the config it applied last. A failing apply is tried again on the next refresh.
+func (r *Rotation) Subscribe(name string, changed func(applied, next *config.Env) bool,
+	apply func(ctx context.Context, next *config.Env) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, &subscription{name: name, changed: changed, apply: apply, applied: r.env})
+}
+
+// Refresh reads the secrets again and applies them to the subscribers they rotated for
+func (r *Rotation) Refresh(ctx context.Context) {
+	next, err := r.env.Refresh(ctx)
+	if err != nil {
+		r.log.Errorf("could not read the secrets, keeping the current ones :: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.subs {
+		if !s.changed(s.applied, next) {
+			continue
+		}
+		if err := s.apply(ctx, next); err != nil {
+			r.log.Errorf("could not apply the rotated secrets of %s, trying again on the next refresh :: %v", s.name, err)
+			continue
+		}
+		s.applied = next
+		r.log.Infof("applied the rotated secrets of %s", s.name)
+	}
+}
+
+func (r *Rotation) start(context.Context) error {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.watch()
+	return nil
+}
+
+func (r *Rotation) watch() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.env.SecretsRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Refresh(context.Background())
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// shutdown stops the refreshes, waiting for a running one to finish
+func (r *Rotation) shutdown(ctx context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,82 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/internal/secrets"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadEnv(t *testing.T, local *secrets.Local) *config.Env {
+	t.Setenv("Admin_Token", "local:admin")
+	r := secrets.NewResolver()
+	r.Register(secrets.SchemeLocal, local)
+	env, err := config.Load(config.ModeStandalone, "", nil, r)
+	require.NoError(t, err)
+	return env
+}
+
+func adminTokenChanged(applied, next *config.Env) bool {
+	return applied.AdminToken != next.AdminToken
+}
+
+func TestRotation_AppliesRotatedSecrets(t *testing.T) {
+	local := secrets.NewLocal(map[string]string{"admin": "v1"})
+	rot := New(loadEnv(t, local), logger.New())
+	var applied []string
+	rot.Subscribe("admin", adminTokenChanged, func(_ context.Context, next *config.Env) error {
+		applied = append(applied, next.AdminToken)
+		return nil
+	})
+
+	rot.Refresh(context.Background())
+	local.Set("admin", "v2")
+	rot.Refresh(context.Background())
+	rot.Refresh(context.Background())
+
+	assert.Equal(t, []string{"v2"}, applied, "applied once, only after the rotation")
+}
+
+func TestRotation_RetriesFailedApply(t *testing.T) {
+	local := secrets.NewLocal(map[string]string{"admin": "v1"})
+	rot := New(loadEnv(t, local), logger.New())
+	calls := 0
+	rot.Subscribe("admin", adminTokenChanged, func(context.Context, *config.Env) error {
+		calls++
+		if calls == 1 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+
+	local.Set("admin", "v2")
+	rot.Refresh(context.Background())
+	rot.Refresh(context.Background())
+	rot.Refresh(context.Background())
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestRotation_SkipsSubscribersWhenRefreshFails(t *testing.T) {
+	t.Setenv("ROTATION_TEST_TOKEN", "v1")
+	t.Setenv("Admin_Token", "env:ROTATION_TEST_TOKEN")
+	env, err := config.Load(config.ModeStandalone, "", nil, secrets.NewResolver())
+	require.NoError(t, err)
+	rot := New(env, logger.New())
+	called := false
+	rot.Subscribe("admin", func(*config.Env, *config.Env) bool { return true }, func(context.Context, *config.Env) error {
+		called = true
+		return nil
+	})
+
+	require.NoError(t, os.Unsetenv("ROTATION_TEST_TOKEN"))
+	rot.Refresh(context.Background())
+
+	assert.False(t, called)
+}
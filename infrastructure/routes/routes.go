@@ -2,36 +2,78 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/infrastructure/metrics"
+	"github.com/rahul-aut-ind/service-user/infrastructure/tracing"
 	controllers "github.com/rahul-aut-ind/service-user/interfaceadapters/controllers"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/middlewares"
 	handlers "github.com/rahul-aut-ind/service-user/interfaceadapters/requesthandler"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
 )
 
 type Routes struct {
 	handler    handlers.RequestHandler
 	controller controllers.Handler
+	health     *controllers.HealthController
 	validator  middlewares.Validator
+	reqLogger  middlewares.RequestLogger
+	limiter    middlewares.RateLimiter
+	idempotent middlewares.Idempotency
+	metrics    *metrics.Metrics
+	tracing    *tracing.Tracing
+	log        *logger.Logger
 }
 
 func New(
 	h handlers.RequestHandler,
 	c controllers.Handler,
+	hc *controllers.HealthController,
 	v middlewares.Validator,
+	rl middlewares.RequestLogger,
+	lim middlewares.RateLimiter,
+	idem middlewares.Idempotency,
+	m *metrics.Metrics,
+	t *tracing.Tracing,
+	l *logger.Logger,
 ) *Routes {
 	return &Routes{
 		handler:    h,
 		controller: c,
+		health:     hc,
 		validator:  v,
+		reqLogger:  rl,
+		limiter:    lim,
+		idempotent: idem,
+		metrics:    m,
+		tracing:    t,
+		log:        l,
 	}
 }
 
 // nolint:dupl // different route groups
 func (r *Routes) Setup() {
+	// apply to every route registered below. The access log runs within the request span
+	// and after the request logger, so it carries the trace and request id
+	r.handler.Gin.Use(r.tracing.Middleware(), r.reqLogger.ScopeLogger(), r.log.DefaultLogger(), r.metrics.Middleware())
+
+	// Probes
+	r.handler.Gin.GET(config.HealthzPath, func(c *gin.Context) { r.health.Healthz(c) })
+	r.handler.Gin.GET(config.ReadyzPath, func(c *gin.Context) { r.health.Readyz(c) })
+	r.handler.Gin.GET(config.MetricsPath, gin.WrapH(r.metrics.Handler()))
+
+	// Admin, the admin token is the credential
+	r.handler.Gin.Group(config.AdminPath).
+		Use(r.validator.ValidateAdmin()).
+		// read the log level
+		GET(config.AdminLogLevelPath, gin.WrapH(r.log.LevelHandler())).
+		// change the log level, {"level":"debug"}
+		PUT(config.AdminLogLevelPath, gin.WrapH(r.log.LevelHandler()))
+
 	// Public
 	r.handler.Gin.Group("/api/v1/users").
-		Use(r.validator.ValidateRequest()).
-		// create user
-		POST("", func(c *gin.Context) { r.controller.CreateUser(c) }).
+		Use(r.validator.ValidateRequest(), r.limiter.Limit(config.RateLimitGroupUsers)).
+		// create user, once per Idempotency-Key
+		POST("", r.idempotent.Idempotent(), func(c *gin.Context) { r.controller.CreateUser(c) }).
 		// update user by id
 		PUT("/:id", func(c *gin.Context) { r.controller.UpdateUser(c) }).
 		// Query specific
@@ -42,9 +84,10 @@ func (r *Routes) Setup() {
 		DELETE("/:id", func(c *gin.Context) { r.controller.DeleteUser(c) })
 
 	r.handler.Gin.Group("/api/v1/user-image").
-		Use(r.validator.ValidateRequest()).
-		// upload a image
-		POST("", func(c *gin.Context) { r.controller.CreateUserImage(c) }).
+		Use(r.validator.ValidateRequest(), r.limiter.Limit(config.RateLimitGroupImages)).
+		// upload a image, limited on top of the other image requests and once per Idempotency-Key
+		POST("", r.limiter.Limit(config.RateLimitGroupUploads), r.idempotent.Idempotent(),
+			func(c *gin.Context) { r.controller.CreateUserImage(c) }).
 		// get an user image
 		GET("/:id", func(c *gin.Context) { r.controller.GetUserImage(c) }).
 		// get all user images
@@ -52,5 +95,47 @@ func (r *Routes) Setup() {
 		// delete an user image
 		DELETE("/:id", func(c *gin.Context) { r.controller.DeleteUserImage(c) }).
 		// deletes all user images
-		DELETE("", func(c *gin.Context) { r.controller.DeleteAllUserImages(c) })
+		DELETE("", func(c *gin.Context) { r.controller.DeleteAllUserImages(c) }).
+		// download all user images as a zip
+		GET("/archive", func(c *gin.Context) { r.controller.GetUserImageArchive(c) }).
+		// get the storage usage of the user against the quota
+		GET("/usage", func(c *gin.Context) { r.controller.GetUserImageUsage(c) }).
+		// replace the tags of an user image
+		PUT("/:id/tags", func(c *gin.Context) { r.controller.SetUserImageTags(c) }).
+		// get user images with a tag
+		GET("/tags/:tag", func(c *gin.Context) { r.controller.GetUserImagesByTag(c) }).
+		// create an album
+		POST("/albums", func(c *gin.Context) { r.controller.CreateAlbum(c) }).
+		// get all albums of the user
+		GET("/albums", func(c *gin.Context) { r.controller.GetAllAlbums(c) }).
+		// rename an album
+		PUT("/albums/:albumId", func(c *gin.Context) { r.controller.RenameAlbum(c) }).
+		// delete an album, the images are kept
+		DELETE("/albums/:albumId", func(c *gin.Context) { r.controller.DeleteAlbum(c) }).
+		// get user images of an album
+		GET("/albums/:albumId/images", func(c *gin.Context) { r.controller.GetAlbumImages(c) }).
+		// add an user image to an album
+		PUT("/albums/:albumId/images/:id", func(c *gin.Context) { r.controller.AddImageToAlbum(c) }).
+		// remove an user image from an album
+		DELETE("/albums/:albumId/images/:id", func(c *gin.Context) { r.controller.RemoveImageFromAlbum(c) }).
+		// share an user image with another user or via a public link
+		POST("/:id/shares", func(c *gin.Context) { r.controller.ShareUserImage(c) }).
+		// get all shares of the user
+		GET("/shares", func(c *gin.Context) { r.controller.GetAllShares(c) }).
+		// revoke a share
+		DELETE("/shares/:shareId", func(c *gin.Context) { r.controller.RevokeShare(c) }).
+		// get an image another user shared with the user
+		GET("/shared/:ownerId/:id", func(c *gin.Context) { r.controller.GetSharedUserImage(c) })
+
+	// Public, the share link is the credential
+	r.handler.Gin.Group(config.PublicSharePath).
+		Use(r.limiter.Limit(config.RateLimitGroupPublic)).
+		// resolve a share link to the image
+		GET("/:shareId", func(c *gin.Context) { r.controller.ResolveShareLink(c) })
+
+	// Public, the signature is the credential. Only serves files when images are stored locally
+	r.handler.Gin.Group(config.LocalFilesPath).
+		Use(r.limiter.Limit(config.RateLimitGroupPublic)).
+		// get an image file behind a signed local url
+		GET("/*key", func(c *gin.Context) { r.controller.GetLocalFile(c) })
 }
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var _ caching.CacheHandler = (*Cache)(nil)
+
+// Cache opens a span for every call to the user cache, lookups tell if they hit
+type Cache struct {
+	next    caching.CacheHandler
+	backend string
+	t       *Tracing
+}
+
+func NewCache(t *Tracing, backend string, next caching.CacheHandler) *Cache {
+	return &Cache{next: next, backend: backend, t: t}
+}
+
+func (c *Cache) Ping(ctx context.Context) error {
+	return ping(ctx, c.next)
+}
+
+// Get is not failed by a miss, it sets the hit attribute instead
+func (c *Cache) Get(ctx context.Context, key string) (string, error) {
+	ctx, span := c.t.start(ctx, c.backend, "Get")
+	defer span.End()
+
+	value, err := c.next.Get(ctx, key)
+	span.SetAttributes(attribute.Bool("service_user.cache_hit", err == nil))
+	return value, err
+}
+
+func (c *Cache) Set(ctx context.Context, key, value string, ttl time.Duration) (err error) {
+	ctx, span := c.t.start(ctx, c.backend, "Set")
+	defer end(span, &err)
+	return c.next.Set(ctx, key, value, ttl)
+}
+
+func (c *Cache) Delete(ctx context.Context, key string) (err error) {
+	ctx, span := c.t.start(ctx, c.backend, "Delete")
+	defer end(span, &err)
+	return c.next.Delete(ctx, key)
+}
@@ -0,0 +1,153 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+)
+
+var _ dynamorepo.DataHandler = (*ImageDB)(nil)
+
+// ImageDB opens a span for every call to the image database
+type ImageDB struct {
+	next    dynamorepo.DataHandler
+	backend string
+	t       *Tracing
+}
+
+func NewImageDB(t *Tracing, backend string, next dynamorepo.DataHandler) *ImageDB {
+	return &ImageDB{next: next, backend: backend, t: t}
+}
+
+func (d *ImageDB) Ping(ctx context.Context) error {
+	return ping(ctx, d.next)
+}
+
+func (d *ImageDB) AddImage(ctx context.Context, p *models.UserImage) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "AddImage")
+	defer end(span, &err)
+	return d.next.AddImage(ctx, p)
+}
+
+func (d *ImageDB) GetAllImagesPaginated(ctx context.Context, req models.PaginatedInput) (_ *models.UserImageResult, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "GetAllImagesPaginated")
+	defer end(span, &err)
+	return d.next.GetAllImagesPaginated(ctx, req)
+}
+
+func (d *ImageDB) GetImage(ctx context.Context, uID, imgID string) (_ *models.UserImage, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "GetImage")
+	defer end(span, &err)
+	return d.next.GetImage(ctx, uID, imgID)
+}
+
+func (d *ImageDB) DeleteImage(ctx context.Context, uID, imgID string) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "DeleteImage")
+	defer end(span, &err)
+	return d.next.DeleteImage(ctx, uID, imgID)
+}
+
+func (d *ImageDB) DeleteAllImages(ctx context.Context, uID string) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "DeleteAllImages")
+	defer end(span, &err)
+	return d.next.DeleteAllImages(ctx, uID)
+}
+
+func (d *ImageDB) SetImageTags(ctx context.Context, uID, imgID string, tags []string) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "SetImageTags")
+	defer end(span, &err)
+	return d.next.SetImageTags(ctx, uID, imgID, tags)
+}
+
+func (d *ImageDB) AddImageToAlbum(ctx context.Context, uID, imgID, albumID string) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "AddImageToAlbum")
+	defer end(span, &err)
+	return d.next.AddImageToAlbum(ctx, uID, imgID, albumID)
+}
+
+func (d *ImageDB) RemoveImageFromAlbum(ctx context.Context, uID, imgID, albumID string) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "RemoveImageFromAlbum")
+	defer end(span, &err)
+	return d.next.RemoveImageFromAlbum(ctx, uID, imgID, albumID)
+}
+
+// IterateImages spans the whole iteration, the calls fn makes show up next to the page queries
+func (d *ImageDB) IterateImages(ctx context.Context, uID string, from, to *time.Time, fn func(ui *models.UserImage) error) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "IterateImages")
+	defer end(span, &err)
+	return d.next.IterateImages(ctx, uID, from, to, fn)
+}
+
+func (d *ImageDB) CreateAlbum(ctx context.Context, a *models.Album) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "CreateAlbum")
+	defer end(span, &err)
+	return d.next.CreateAlbum(ctx, a)
+}
+
+func (d *ImageDB) GetAlbum(ctx context.Context, uID, albumID string) (_ *models.Album, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "GetAlbum")
+	defer end(span, &err)
+	return d.next.GetAlbum(ctx, uID, albumID)
+}
+
+func (d *ImageDB) ListAlbums(ctx context.Context, uID string) (_ []models.Album, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "ListAlbums")
+	defer end(span, &err)
+	return d.next.ListAlbums(ctx, uID)
+}
+
+func (d *ImageDB) RenameAlbum(ctx context.Context, uID, albumID, name string) (_ *models.Album, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "RenameAlbum")
+	defer end(span, &err)
+	return d.next.RenameAlbum(ctx, uID, albumID, name)
+}
+
+func (d *ImageDB) DeleteAlbum(ctx context.Context, uID, albumID string) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "DeleteAlbum")
+	defer end(span, &err)
+	return d.next.DeleteAlbum(ctx, uID, albumID)
+}
+
+func (d *ImageDB) GetUsage(ctx context.Context, uID string) (_ *models.Usage, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "GetUsage")
+	defer end(span, &err)
+	return d.next.GetUsage(ctx, uID)
+}
+
+func (d *ImageDB) ReserveUsage(ctx context.Context, uID string, size int64, quota models.Quota) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "ReserveUsage")
+	defer end(span, &err)
+	return d.next.ReserveUsage(ctx, uID, size, quota)
+}
+
+func (d *ImageDB) ReleaseUsage(ctx context.Context, uID string, size int64) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "ReleaseUsage")
+	defer end(span, &err)
+	return d.next.ReleaseUsage(ctx, uID, size)
+}
+
+func (d *ImageDB) CreateShare(ctx context.Context, sh *models.Share) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "CreateShare")
+	defer end(span, &err)
+	return d.next.CreateShare(ctx, sh)
+}
+
+func (d *ImageDB) GetShare(ctx context.Context, shareID string) (_ *models.Share, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "GetShare")
+	defer end(span, &err)
+	return d.next.GetShare(ctx, shareID)
+}
+
+func (d *ImageDB) ListShares(ctx context.Context, ownerID, imgID string) (_ []models.Share, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "ListShares")
+	defer end(span, &err)
+	return d.next.ListShares(ctx, ownerID, imgID)
+}
+
+func (d *ImageDB) DeleteShare(ctx context.Context, ownerID, shareID string) (err error) {
+	ctx, span := d.t.start(ctx, d.backend, "DeleteShare")
+	defer end(span, &err)
+	return d.next.DeleteShare(ctx, ownerID, shareID)
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/fsrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	_ s3repo.S3Handler        = (*ImageStorage)(nil)
+	_ fsrepo.SignedFileOpener = (*ImageStorage)(nil)
+)
+
+// ImageStorage opens a span for every call to the image storage
+type ImageStorage struct {
+	next    s3repo.S3Handler
+	backend string
+	t       *Tracing
+}
+
+func NewImageStorage(t *Tracing, backend string, next s3repo.S3Handler) *ImageStorage {
+	return &ImageStorage{next: next, backend: backend, t: t}
+}
+
+func (s *ImageStorage) Ping(ctx context.Context) error {
+	return ping(ctx, s.next)
+}
+
+func (s *ImageStorage) Save(ctx context.Context, uID string, imageID uuid.UUID, ext string, f *[]byte) (_ string, err error) {
+	ctx, span := s.t.start(ctx, s.backend, "Save")
+	span.SetAttributes(attribute.Int("service_user.upload_size", len(*f)))
+	defer end(span, &err)
+	return s.next.Save(ctx, uID, imageID, ext, f)
+}
+
+func (s *ImageStorage) Delete(ctx context.Context, uID string, imageID string) (err error) {
+	ctx, span := s.t.start(ctx, s.backend, "Delete")
+	defer end(span, &err)
+	return s.next.Delete(ctx, uID, imageID)
+}
+
+// DeleteAll marks the span failed if any file was left behind, not only if listing the files failed
+func (s *ImageStorage) DeleteAll(ctx context.Context, uID string) (*s3repo.DeleteResult, error) {
+	ctx, span := s.t.start(ctx, s.backend, "DeleteAll")
+	result, err := s.next.DeleteAll(ctx, uID)
+	failed := err
+	if failed == nil && result != nil {
+		span.SetAttributes(attribute.Int("service_user.deleted", result.Deleted))
+		failed = result.Err()
+	}
+	end(span, &failed)
+	return result, err
+}
+
+func (s *ImageStorage) GetPresignedURL(ctx context.Context, key string, expiry time.Duration) (_ string, err error) {
+	ctx, span := s.t.start(ctx, s.backend, "GetPresignedURL")
+	defer end(span, &err)
+	return s.next.GetPresignedURL(ctx, key, expiry)
+}
+
+// Get spans opening the object, reading it is up to the caller
+func (s *ImageStorage) Get(ctx context.Context, key string) (_ io.ReadCloser, err error) {
+	ctx, span := s.t.start(ctx, s.backend, "Get")
+	defer end(span, &err)
+	return s.next.Get(ctx, key)
+}
+
+// OpenSigned serves the signed local urls if the decorated storage is the local filesystem
+func (s *ImageStorage) OpenSigned(ctx context.Context, key, expires, signature string) (_ io.ReadCloser, err error) {
+	opener, ok := s.next.(fsrepo.SignedFileOpener)
+	if !ok {
+		return nil, fmt.Errorf("%s storage does not serve signed local urls", s.backend)
+	}
+	ctx, span := s.t.start(ctx, s.backend, "OpenSigned")
+	defer end(span, &err)
+	return opener.OpenSigned(ctx, key, expires, signature)
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/infrastructure/lifecycle"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type (
+	// Tracing starts the spans of the service, sending them to the exporter of the config
+	Tracing struct {
+		provider   trace.TracerProvider
+		tracer     trace.Tracer
+		propagator propagation.TextMapPropagator
+	}
+
+	// pinger is the health.Pinger of the decorated backends, forwarded by the decorators
+	pinger interface {
+		Ping(ctx context.Context) error
+	}
+)
+
+const (
+	// ServiceName names the service in the spans
+	ServiceName = "service-user"
+	// attrBackend is the span attribute naming the backend a call went to
+	attrBackend = "service_user.backend"
+)
+
+// New sets up the exporter of the config. Spans are flushed when the app stops.
+// Without an exporter no spans are recorded, but the trace context of incoming requests is
+// still passed on, so the logs carry the trace id of the caller.
+func New(env *config.Env, l *logger.Logger, lc *lifecycle.Lifecycle) *Tracing {
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+	exporter, err := newExporter(env)
+	if err != nil {
+		l.Fatalf("could not create %s trace exporter :: %v", env.TracingExporter, err)
+	}
+
+	var provider trace.TracerProvider = noop.NewTracerProvider()
+	if exporter != nil {
+		sdkProvider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(env.TracingSampleRatio))),
+		)
+		lc.Append(lifecycle.Hook{Name: "tracing", OnStop: sdkProvider.Shutdown})
+		l.Infof("exporting traces to %s", env.TracingExporter)
+		provider = sdkProvider
+	}
+
+	// instrumented libraries pick the globals up
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return newTracing(provider, propagator)
+}
+
+func newTracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *Tracing {
+	return &Tracing{
+		provider:   provider,
+		tracer:     provider.Tracer(ServiceName),
+		propagator: propagator,
+	}
+}
+
+func newExporter(env *config.Env) (sdktrace.SpanExporter, error) {
+	switch env.TracingExporter {
+	case config.TracingExporterNone, "":
+		return nil, nil
+	case config.TracingExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case config.TracingExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(env.OTLPEndpoint)}
+		if env.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		// the exporter connects lazily, an unreachable collector does not fail the boot
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter")
+	}
+}
+
+// Middleware continues the trace of the W3C traceparent header, or starts one, with a span per
+// request named by the route template. Probes and metric scrapes are left out.
+func (t *Tracing) Middleware() gin.HandlerFunc {
+	return otelgin.Middleware(ServiceName,
+		otelgin.WithTracerProvider(t.provider),
+		otelgin.WithPropagators(t.propagator),
+		otelgin.WithGinFilter(func(c *gin.Context) bool {
+			switch c.FullPath() {
+			case config.HealthzPath, config.ReadyzPath, config.MetricsPath:
+				return false
+			}
+			return true
+		}),
+	)
+}
+
+// start opens a client span for a call to backend, to be ended with end
+func (t *Tracing) start(ctx context.Context, backend, operation string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, backend+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String(attrBackend, backend)),
+	)
+}
+
+// end ends the span, marking it failed if the call returned an error that is not the caller's fault
+func end(span trace.Span, err *error) {
+	if *err != nil && !errors.IsClientError(*err) {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// ping forwards a readiness check to the decorated backend, a backend that can not be pinged is taken as up
+func ping(ctx context.Context, next any) error {
+	if p, ok := next.(pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
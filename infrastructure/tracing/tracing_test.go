@@ -0,0 +1,179 @@
+package tracing
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/fsrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/memrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/rahul-aut-ind/service-user/services/imageservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// findFunc is a user database answering FindRecord with f, the other methods are not used
+type findFunc struct {
+	mysqlrepo.DataHandler
+	f func(id string) (*models.User, error)
+}
+
+func (ff findFunc) FindRecord(_ context.Context, id string) (*models.User, error) {
+	return ff.f(id)
+}
+
+func newTestTracing() (*Tracing, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	return newTracing(provider, propagation.TraceContext{}), rec
+}
+
+func spanNamed(t *testing.T, rec *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	for _, s := range rec.Ended() {
+		if s.Name() == name {
+			return s
+		}
+	}
+	require.Failf(t, "span not found", "no span named %s", name)
+	return nil
+}
+
+func TestMiddleware_ContinuesTraceparent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tr, rec := newTestTracing()
+	cache := NewCache(tr, config.CacheMemory, caching.NewMemoryCache(logger.New()))
+
+	e := gin.New()
+	e.ContextWithFallback = true
+	e.Use(tr.Middleware())
+	e.GET("/users/:id", func(c *gin.Context) {
+		_, _ = cache.Get(c, c.Param("id"))
+		c.Status(http.StatusOK)
+	})
+	e.GET(config.HealthzPath, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("traceparent", traceparent)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, config.HealthzPath, nil))
+
+	require.Len(t, rec.Ended(), 2, "probes are not traced")
+	server := spanNamed(t, rec, "/users/:id")
+	lookup := spanNamed(t, rec, "memory.Get")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), lookup.Parent().SpanID())
+}
+
+func TestImageService_ParallelDeletesJoinTheTrace(t *testing.T) {
+	tr, rec := newTestTracing()
+	l := logger.New()
+	env := &config.Env{LocalStorageDir: t.TempDir()}
+	svc := imageservice.New(
+		NewImageDB(tr, config.ImageDBMemory, memrepo.New(l)),
+		NewImageStorage(tr, config.ImageStorageFS, fsrepo.New(l, env)),
+		env, l,
+	)
+
+	ctx, parent := tr.tracer.Start(context.Background(), "request")
+	require.NoError(t, svc.DeleteAllByUserID(ctx, "1"))
+	parent.End()
+
+	for _, name := range []string{"memory.DeleteAllImages", "fs.DeleteAll"} {
+		s := spanNamed(t, rec, name)
+		assert.Equal(t, parent.SpanContext().TraceID(), s.SpanContext().TraceID(), name)
+		assert.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID(), name)
+	}
+}
+
+// fakeDynamoDB answers the Query of a user's images with n images and every UpdateItem with success
+func fakeDynamoDB(n int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if !strings.HasSuffix(r.Header.Get("X-Amz-Target"), ".Query") {
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+		items := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			items = append(items, fmt.Sprintf(`{"UserID":{"S":"1"},"ImageID":{"S":"img-%d"},"Size":{"N":"10"}}`, i))
+		}
+		_, _ = fmt.Fprintf(w, `{"Count":%d,"Items":[%s]}`, n, strings.Join(items, ","))
+	}))
+}
+
+func TestDynamoDB_DeleteAllImagesTracesEveryImage(t *testing.T) {
+	tr, rec := newTestTracing()
+	// the repo starts its spans from the global provider
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tr.provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	srv := fakeDynamoDB(3)
+	defer srv.Close()
+	repo := &dynamorepo.DynamoDBRepo{
+		TableName: "user-images",
+		Log:       logger.New(),
+		Client: dynamodb.New(dynamodb.Options{
+			Region:       "eu-central-1",
+			BaseEndpoint: aws.String(srv.URL),
+			Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		}),
+	}
+
+	require.NoError(t, NewImageDB(tr, config.ImageDBDynamo, repo).DeleteAllImages(context.Background(), "1"))
+
+	parent := spanNamed(t, rec, "dynamodb.DeleteAllImages")
+	images := map[string]bool{}
+	for _, s := range rec.Ended() {
+		if s.Name() != "dynamodb.DeleteAllImages.image" {
+			continue
+		}
+		assert.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+		for _, a := range s.Attributes() {
+			images[a.Value.AsString()] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{"img-0": true, "img-1": true, "img-2": true}, images)
+}
+
+func TestUserDB_MarksOnlyBackendFailures(t *testing.T) {
+	tr, rec := newTestTracing()
+	db := NewUserDB(tr, config.DBDriverMySQL, findFunc{f: func(id string) (*models.User, error) {
+		if id == "down" {
+			return nil, stderrors.New("connection refused")
+		}
+		return nil, errors.New(errors.ErrCodeNoUser, stderrors.New("no user"))
+	}})
+
+	_, _ = db.FindRecord(context.Background(), "missing")
+	_, _ = db.FindRecord(context.Background(), "down")
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "mysql.FindRecord", spans[1].Name())
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+)
+
+var (
+	_ mysqlrepo.DataHandler   = (*UserDB)(nil)
+	_ mysqlrepo.PrimaryPinner = (*UserDB)(nil)
+)
+
+// UserDB opens a span for every call to the user database
+type UserDB struct {
+	next    mysqlrepo.DataHandler
+	backend string
+	t       *Tracing
+}
+
+func NewUserDB(t *Tracing, backend string, next mysqlrepo.DataHandler) *UserDB {
+	return &UserDB{next: next, backend: backend, t: t}
+}
+
+func (d *UserDB) Ping(ctx context.Context) error {
+	return ping(ctx, d.next)
+}
+
+// Primary keeps reads pinned to the primary traced
+func (d *UserDB) Primary() mysqlrepo.DataHandler {
+	if p, ok := d.next.(mysqlrepo.PrimaryPinner); ok {
+		return NewUserDB(d.t, d.backend, p.Primary())
+	}
+	return d
+}
+
+func (d *UserDB) ListRecords(ctx context.Context) (_ []models.User, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "ListRecords")
+	defer end(span, &err)
+	return d.next.ListRecords(ctx)
+}
+
+func (d *UserDB) FindRecord(ctx context.Context, id string) (_ *models.User, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "FindRecord")
+	defer end(span, &err)
+	return d.next.FindRecord(ctx, id)
+}
+
+func (d *UserDB) CreateRecord(ctx context.Context, u *models.User) (_ *models.User, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "CreateRecord")
+	defer end(span, &err)
+	return d.next.CreateRecord(ctx, u)
+}
+
+func (d *UserDB) UpdateRecord(ctx context.Context, u *models.User) (_ *models.User, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "UpdateRecord")
+	defer end(span, &err)
+	return d.next.UpdateRecord(ctx, u)
+}
+
+func (d *UserDB) DeleteRecord(ctx context.Context, u *models.User) (_ *models.User, err error) {
+	ctx, span := d.t.start(ctx, d.backend, "DeleteRecord")
+	defer end(span, &err)
+	return d.next.DeleteRecord(ctx, u)
+}
@@ -0,0 +1,12 @@
+//go:build wireinject
+// +build wireinject
+
+package tracing
+
+import (
+	"github.com/google/wire"
+)
+
+var Wired = wire.NewSet(
+	New,
+)
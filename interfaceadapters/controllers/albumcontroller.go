@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+)
+
+func (uc *Controller) SetUserImageTags(c Context) {
+	imageID := c.Param("id")
+	if !(imageIDRegExp.MatchString(imageID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	req := &models.TagsRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+	if err := uc.validateInput(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+
+	resp, err := uc.imageService.SetUserImageTags(c, userID, imageID, req.Tags)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (uc *Controller) GetUserImagesByTag(c Context) {
+	tag := c.Param(config.PathParamTag)
+	if strings.TrimSpace(tag) == "" {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	request := paginatedInput(c, userID)
+	request.Tag = tag
+
+	resp, err := uc.imageService.GetAllUserImages(c, request)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (uc *Controller) CreateAlbum(c Context) {
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	req := &models.AlbumRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+	if err := uc.validateInput(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+
+	resp, err := uc.imageService.CreateAlbum(c, userID, req.Name)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (uc *Controller) GetAllAlbums(c Context) {
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	resp, err := uc.imageService.GetAllAlbums(c, userID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (uc *Controller) RenameAlbum(c Context) {
+	albumID := c.Param(config.PathParamAlbumID)
+	if !(albumIDRegExp.MatchString(albumID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	req := &models.AlbumRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+	if err := uc.validateInput(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+
+	resp, err := uc.imageService.RenameAlbum(c, userID, albumID, req.Name)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (uc *Controller) DeleteAlbum(c Context) {
+	albumID := c.Param(config.PathParamAlbumID)
+	if !(albumIDRegExp.MatchString(albumID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	err := uc.imageService.DeleteAlbum(c, userID, albumID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, nil)
+}
+
+func (uc *Controller) GetAlbumImages(c Context) {
+	albumID := c.Param(config.PathParamAlbumID)
+	if !(albumIDRegExp.MatchString(albumID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	request := paginatedInput(c, userID)
+	request.AlbumID = albumID
+
+	resp, err := uc.imageService.GetAllUserImages(c, request)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (uc *Controller) AddImageToAlbum(c Context) {
+	albumID, imageID, userID, ok := uc.albumImageParams(c)
+	if !ok {
+		return
+	}
+
+	err := uc.imageService.AddImageToAlbum(c, userID, albumID, imageID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, nil)
+}
+
+func (uc *Controller) RemoveImageFromAlbum(c Context) {
+	albumID, imageID, userID, ok := uc.albumImageParams(c)
+	if !ok {
+		return
+	}
+
+	err := uc.imageService.RemoveImageFromAlbum(c, userID, albumID, imageID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, nil)
+}
+
+// albumImageParams validates the album id, image id and user id of an album membership request
+func (uc *Controller) albumImageParams(c Context) (albumID, imageID, userID string, ok bool) {
+	albumID = c.Param(config.PathParamAlbumID)
+	imageID = c.Param("id")
+	if !(albumIDRegExp.MatchString(albumID)) || !(imageIDRegExp.MatchString(imageID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return "", "", "", false
+	}
+
+	userID = c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return "", "", "", false
+	}
+
+	return albumID, imageID, userID, true
+}
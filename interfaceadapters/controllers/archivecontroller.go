@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+)
+
+const (
+	archiveFileName = "images.zip"
+	dateLayout      = "2006-01-02"
+	// archiveWriteTimeout bounds streaming an archive, the server write timeout is too short for large ones
+	archiveWriteTimeout = time.Hour
+)
+
+// GetUserImageArchive streams a zip of the user's images, optionally limited to those taken between from and to
+func (uc *Controller) GetUserImageArchive(c Context) {
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	from, err := parseRangeTime(c.Query(config.QueryParamFrom), false)
+	if err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+	to, err := parseRangeTime(c.Query(config.QueryParamTo), true)
+	if err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+
+	req := models.ArchiveInput{UserID: userID, From: from, To: to}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", archiveFileName))
+	c.Stream(func(w io.Writer) bool {
+		if err := extendWriteDeadline(w, archiveWriteTimeout); err != nil {
+			uc.log.For(c).Warnf("could not extend the write deadline of the archive of user %s :: %s", userID, err)
+		}
+		// the status is sent with the first bytes, so a failure can only cut the archive short
+		if err := uc.imageService.WriteArchive(c, req, w); err != nil {
+			uc.log.For(c).Errorf("error streaming archive of user %s :: %s", userID, err)
+		}
+		return false
+	})
+}
+
+// extendWriteDeadline moves the write deadline of the response written through w to timeout from now
+func extendWriteDeadline(w io.Writer, timeout time.Duration) error {
+	rw, ok := w.(http.ResponseWriter)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return http.NewResponseController(rw).SetWriteDeadline(time.Now().Add(timeout))
+}
+
+// parseRangeTime reads a RFC3339 time or a plain date, a plain date ending a range covers the whole day
+func parseRangeTime(v string, endOfDay bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(dateLayout, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %s", v)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendWriteDeadline_OutlastsServerWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/archive", func(c *gin.Context) {
+		c.Stream(func(w io.Writer) bool {
+			require.NoError(t, extendWriteDeadline(w, time.Minute))
+			for i := 0; i < 3; i++ {
+				_, _ = w.Write([]byte("chunk"))
+				c.Writer.Flush()
+				time.Sleep(100 * time.Millisecond)
+			}
+			return false
+		})
+	})
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.Config.WriteTimeout = 150 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/archive")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "chunkchunkchunk", string(body))
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+)
+
+// GetLocalFile is public, it serves an image file behind a signed url when images are stored locally
+func (uc *Controller) GetLocalFile(c Context) {
+	// the wildcard param keeps the leading slash
+	key := strings.TrimPrefix(c.Param(config.PathParamKey), "/")
+	if key == "" {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	f, err := uc.imageService.OpenLocalFile(c, key, c.Query(config.QueryParamExpires), c.Query(config.QueryParamSignature))
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+	defer f.Close()
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	c.Stream(func(w io.Writer) bool {
+		if _, err := io.Copy(w, f); err != nil {
+			uc.log.For(c).Errorf("error streaming file %s :: %s", key, err)
+		}
+		return false
+	})
+}
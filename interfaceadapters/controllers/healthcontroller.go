@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/rahul-aut-ind/service-user/infrastructure/health"
+)
+
+// HealthController answers the liveness and readiness probes, outside the api and its auth
+type HealthController struct {
+	checker *health.Checker
+}
+
+func NewHealthController(hc *health.Checker) *HealthController {
+	return &HealthController{checker: hc}
+}
+
+// Healthz answers as long as the process is able to serve requests at all
+func (hc *HealthController) Healthz(c Context) {
+	c.JSON(http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// Readyz checks every dependency and answers 503 with the report if any of them failed
+func (hc *HealthController) Readyz(c Context) {
+	report := hc.checker.Ready(c)
+
+	code := http.StatusOK
+	if report.Status != health.StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
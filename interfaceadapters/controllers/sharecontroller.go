@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+)
+
+func (uc *Controller) ShareUserImage(c Context) {
+	imageID := c.Param("id")
+	if !(imageIDRegExp.MatchString(imageID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	req := &models.ShareRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+	if err := uc.validateInput(req); err != nil {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request. Err :: %v", err)))
+		return
+	}
+
+	resp, err := uc.imageService.ShareUserImage(c, userID, imageID, req)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (uc *Controller) GetAllShares(c Context) {
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	imageID := c.Query(config.QueryParamImageID)
+	if imageID != "" && !(imageIDRegExp.MatchString(imageID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	resp, err := uc.imageService.GetAllShares(c, userID, imageID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (uc *Controller) RevokeShare(c Context) {
+	shareID := c.Param(config.PathParamShareID)
+	if !(shareIDRegExp.MatchString(shareID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	err := uc.imageService.RevokeShare(c, userID, shareID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, nil)
+}
+
+func (uc *Controller) GetSharedUserImage(c Context) {
+	ownerID := c.Param(config.PathParamOwnerID)
+	imageID := c.Param("id")
+	if !(userIDRegExp.MatchString(ownerID)) || !(imageIDRegExp.MatchString(imageID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	resp, err := uc.imageService.GetSharedUserImage(c, userID, ownerID, imageID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ResolveShareLink is public, it redirects the holder of a valid share link to the image
+func (uc *Controller) ResolveShareLink(c Context) {
+	token := c.Param(config.PathParamShareID)
+	if !(shareIDRegExp.MatchString(token)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	url, err := uc.imageService.ResolveShareLink(c, token, c.GetHeader(config.HeaderSharePassword))
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -34,10 +36,27 @@ type (
 		GetAllUserImages(c Context)
 		DeleteUserImage(c Context)
 		DeleteAllUserImages(c Context)
+		GetUserImageUsage(c Context)
+		GetUserImageArchive(c Context)
+		SetUserImageTags(c Context)
+		GetUserImagesByTag(c Context)
+		CreateAlbum(c Context)
+		GetAllAlbums(c Context)
+		RenameAlbum(c Context)
+		DeleteAlbum(c Context)
+		GetAlbumImages(c Context)
+		AddImageToAlbum(c Context)
+		RemoveImageFromAlbum(c Context)
+		ShareUserImage(c Context)
+		GetAllShares(c Context)
+		RevokeShare(c Context)
+		GetSharedUserImage(c Context)
+		ResolveShareLink(c Context)
+		GetLocalFile(c Context)
 	}
 
 	Controller struct {
-		rc           caching.CacheHandler
+		users        *caching.ReadThrough
 		userService  userservice.UserService
 		imageService imageservice.UserImageService
 		log          *logger.Logger
@@ -55,6 +74,9 @@ type (
 		Done() <-chan struct{}
 		Deadline() (deadline time.Time, ok bool)
 		Copy() *gin.Context
+		Redirect(code int, location string)
+		Header(key, value string)
+		Stream(step func(w io.Writer) bool) bool
 	}
 )
 
@@ -66,11 +88,13 @@ const (
 var (
 	userIDRegExp  = regexp.MustCompile(`^\d+$`)
 	imageIDRegExp = regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[1-5][a-fA-F0-9]{3}-[89abAB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$`)
+	albumIDRegExp = imageIDRegExp
+	shareIDRegExp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
 )
 
-func New(rc caching.CacheHandler, us userservice.UserService, is imageservice.UserImageService, l *logger.Logger) *Controller {
+func New(users *caching.ReadThrough, us userservice.UserService, is imageservice.UserImageService, l *logger.Logger) *Controller {
 	return &Controller{
-		rc:           rc,
+		users:        users,
 		userService:  us,
 		imageService: is,
 		log:          l,
@@ -98,15 +122,15 @@ func (uc *Controller) CreateUser(c Context) {
 		Age:     req.Age,
 	}
 
-	user, err := uc.userService.AddUser(newUser)
+	user, err := uc.userService.AddUser(c, newUser)
 	if err != nil {
 		uc.handleError(c, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error :: %v", err)))
 		return
 	}
 	u, _ := json.Marshal(user)
-	err = uc.rc.Set(c, strconv.Itoa(int(user.ID)), string(u), caching.DefaultTTL)
+	err = uc.users.Set(c, userCacheKey(strconv.Itoa(int(user.ID))), string(u))
 	if err != nil {
-		uc.log.Warnf("err updating cache :: %s", err)
+		uc.log.For(c).Warnf("err updating cache :: %s", err)
 	}
 	c.JSON(http.StatusAccepted, &models.Response{Data: user})
 }
@@ -118,32 +142,30 @@ func (uc *Controller) FindUser(c Context) {
 		return
 	}
 
-	// check if data exists in redis
-	cachedData, err := uc.rc.Get(c, userID)
-	if err != nil {
-		uc.log.Debug("cache miss")
-		user, err := uc.userService.GetUserWithID(userID)
+	cachedData, err := uc.users.Get(c, userCacheKey(userID), func(ctx context.Context) (string, error) {
+		user, err := uc.userService.GetUserWithID(ctx, userID)
 		if err != nil {
 			if strings.Contains(err.Error(), errors.ErrCodeNoUser) {
-				uc.handleError(c, errors.New(errors.ErrCodeNoUser, fmt.Errorf("error :: %v", err)))
-				return
+				return "", caching.NotFound(err)
 			}
-			uc.handleError(c, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error :: %v", err)))
-			return
+			return "", err
 		}
-		u, _ := json.Marshal(user)
-		err = uc.rc.Set(c, userID, string(u), caching.DefaultTTL)
-		if err != nil {
-			uc.log.Warnf("err updating cache :: %s", err)
+		u, err := json.Marshal(user)
+		return string(u), err
+	})
+	if err != nil {
+		if stderrors.Is(err, caching.ErrNotFound) {
+			uc.handleError(c, errors.New(errors.ErrCodeNoUser, fmt.Errorf("error :: %v", err)))
+			return
 		}
-		c.JSON(http.StatusOK, &models.Response{Data: user})
+		uc.handleError(c, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error :: %v", err)))
 		return
 	}
-	uc.log.Debug("serving data from cache..")
 	data := models.User{}
 	err = json.Unmarshal([]byte(cachedData), &data)
 	if err != nil {
 		uc.handleError(c, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error :: %v", err)))
+		return
 	}
 	c.JSON(http.StatusOK, &models.Response{Data: &data})
 }
@@ -155,7 +177,7 @@ func (uc *Controller) DeleteUser(c Context) {
 		return
 	}
 
-	err := uc.userService.DeleteUser(userID)
+	err := uc.userService.DeleteUser(c, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), errors.ErrCodeNoUser) {
 			uc.handleError(c, errors.New(errors.ErrCodeNoUser, fmt.Errorf("error :: %v", err)))
@@ -164,9 +186,9 @@ func (uc *Controller) DeleteUser(c Context) {
 		uc.handleError(c, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error :: %v", err)))
 		return
 	}
-	err = uc.rc.Delete(c, userID)
+	err = uc.users.Delete(c, userCacheKey(userID))
 	if err != nil {
-		uc.log.Warnf("err updating cache :: %s", err)
+		uc.log.For(c).Warnf("err updating cache :: %s", err)
 	}
 	c.JSON(http.StatusAccepted, &models.Response{Data: RequestAccepted})
 }
@@ -196,7 +218,7 @@ func (uc *Controller) UpdateUser(c Context) {
 		Age:     req.Age,
 	}
 
-	user, err := uc.userService.UpdateUser(userID, updatedUserInfo)
+	user, err := uc.userService.UpdateUser(c, userID, updatedUserInfo)
 	if err != nil {
 		if strings.Contains(err.Error(), errors.ErrCodeNoUser) {
 			uc.handleError(c, errors.New(errors.ErrCodeNoUser, fmt.Errorf("error :: %v", err)))
@@ -206,15 +228,15 @@ func (uc *Controller) UpdateUser(c Context) {
 		return
 	}
 	u, _ := json.Marshal(user)
-	err = uc.rc.Set(c, userID, string(u), caching.DefaultTTL)
+	err = uc.users.Set(c, userCacheKey(userID), string(u))
 	if err != nil {
-		uc.log.Warnf("err updating cache :: %s", err)
+		uc.log.For(c).Warnf("err updating cache :: %s", err)
 	}
 	c.JSON(http.StatusOK, &models.Response{Data: user})
 }
 
 func (uc *Controller) FindAllUsers(c Context) {
-	user, err := uc.userService.GetAllUsers()
+	user, err := uc.userService.GetAllUsers(c)
 	if err != nil {
 		uc.handleError(c, errors.New(errors.ErrCodeNoUser, fmt.Errorf("error :: %v", err)))
 		return
@@ -246,7 +268,7 @@ func (uc *Controller) CreateUserImage(c Context) {
 		return
 	}
 
-	resp, err := uc.imageService.SaveUserImage(userID, req)
+	resp, err := uc.imageService.SaveUserImage(c, userID, req)
 	if err != nil {
 		uc.handleError(c, err)
 		return
@@ -268,7 +290,7 @@ func (uc *Controller) GetUserImage(c Context) {
 		return
 	}
 
-	resp, err := uc.imageService.GetByUserIDImageID(userID, imageID)
+	resp, err := uc.imageService.GetByUserIDImageID(c, userID, imageID)
 	if err != nil {
 		uc.handleError(c, err)
 		return
@@ -285,22 +307,9 @@ func (uc *Controller) GetAllUserImages(c Context) {
 		return
 	}
 
-	qpLastKey := c.Query(config.QueryParamLastKey)
-	qpTakenAt := c.Query(config.QueryParamlastKeyDate)
-	qpLimit := c.Query(config.QueryParamLimit)
-	limit, err := strconv.ParseInt(qpLimit, 10, 32)
-	if err != nil {
-		limit = DefaultPageItemLimit
-	}
-
-	request := models.PaginatedInput{
-		UserID:           userID,
-		LastImageID:      qpLastKey,
-		LastImageTakenAt: qpTakenAt,
-		Limit:            int32(limit),
-	}
+	request := paginatedInput(c, userID)
 
-	resp, err := uc.imageService.GetAllUserImages(request)
+	resp, err := uc.imageService.GetAllUserImages(c, request)
 	if err != nil {
 		uc.handleError(c, err)
 		return
@@ -322,7 +331,7 @@ func (uc *Controller) DeleteUserImage(c Context) {
 		return
 	}
 
-	err := uc.imageService.DeleteByUserIDImageID(userID, imageID)
+	err := uc.imageService.DeleteByUserIDImageID(c, userID, imageID)
 	if err != nil {
 		uc.handleError(c, err)
 		return
@@ -338,7 +347,7 @@ func (uc *Controller) DeleteAllUserImages(c Context) {
 		return
 	}
 
-	err := uc.imageService.DeleteAllByUserID(userID)
+	err := uc.imageService.DeleteAllByUserID(c, userID)
 	if err != nil {
 		uc.handleError(c, err)
 		return
@@ -347,7 +356,46 @@ func (uc *Controller) DeleteAllUserImages(c Context) {
 	c.JSON(http.StatusAccepted, nil)
 }
 
-func (uc *Controller) validateInput(input models.Request) error {
+func (uc *Controller) GetUserImageUsage(c Context) {
+	userID := c.GetHeader(config.HeaderUserID)
+	if !(userIDRegExp.MatchString(userID)) {
+		uc.handleError(c, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("bad request")))
+		return
+	}
+
+	resp, err := uc.imageService.GetUsage(c, userID)
+	if err != nil {
+		uc.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// paginatedInput reads the pagination query params of the request
+func paginatedInput(c Context, userID string) models.PaginatedInput {
+	qpLastKey := c.Query(config.QueryParamLastKey)
+	qpTakenAt := c.Query(config.QueryParamlastKeyDate)
+	qpLimit := c.Query(config.QueryParamLimit)
+	limit, err := strconv.ParseInt(qpLimit, 10, 32)
+	if err != nil {
+		limit = DefaultPageItemLimit
+	}
+
+	return models.PaginatedInput{
+		UserID:           userID,
+		LastImageID:      qpLastKey,
+		LastImageTakenAt: qpTakenAt,
+		Limit:            int32(limit),
+	}
+}
+
+// userCacheKey is the key of a user in the cache
+func userCacheKey(userID string) string {
+	return "user:" + userID
+}
+
+func (uc *Controller) validateInput(input interface{}) error {
 	return uc.val.Struct(input)
 }
 
@@ -358,6 +406,6 @@ func (uc *Controller) handleError(c Context, err error) {
 	} else {
 		apiErr = errors.New(errors.ErrCodeGeneric, err)
 	}
-	uc.log.Errorf("error :: %s", err)
+	uc.log.For(c).Errorf("error :: %s", err)
 	c.JSON(apiErr.HTTPCode(), apiErr)
 }
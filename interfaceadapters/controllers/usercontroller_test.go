@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/rahul-aut-ind/service-user/pkg/logger"
 	"github.com/rahul-aut-ind/service-user/services/imageservice"
@@ -11,8 +10,10 @@ import (
 	"github.com/rahul-aut-ind/service-user/domain/errors"
 	"github.com/rahul-aut-ind/service-user/domain/models"
 	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/internal/config"
 	"github.com/rahul-aut-ind/service-user/mocks"
 	"github.com/rahul-aut-ind/service-user/services/userservice"
+	"github.com/stretchr/testify/mock"
 )
 
 var (
@@ -26,19 +27,21 @@ var (
 func TestController_FindUser_Success(t *testing.T) {
 	repoMoc := new(mocks.DBRepo)
 	contextMoc := new(mocks.Context)
+	// no request-scoped logger, the controller logs through its own
+	contextMoc.On("Value", mock.Anything).Return(nil)
 	cacheMoc := new(mocks.CacheHandler)
 
 	contextMoc.On("Param", "id").Return("1")
 	contextMoc.On("JSON", http.StatusOK, &models.Response{Data: testUserResp})
 
 	testService := userservice.New(repoMoc, logger.New())
-	testImageService := imageservice.New(nil, nil, logger.New())
-	testContrlr := New(cacheMoc, testService, testImageService, logger.New())
+	testImageService := imageservice.New(nil, nil, &config.Env{}, logger.New())
+	testContrlr := New(caching.NewReadThrough(cacheMoc, config.Defaults(config.ModeService), logger.New()), testService, testImageService, logger.New())
 
-	cacheMoc.On("Get", contextMoc, "1").Return("", errors.New("err : %s", fmt.Errorf("no data in cache")))
-	repoMoc.On("FindRecord", "1").Return(testUserResp, nil)
-	u, _ := json.Marshal(testUserResp)
-	cacheMoc.On("Set", contextMoc, "1", string(u), caching.DefaultTTL).Return(nil)
+	cacheMoc.On("Get", contextMoc, "user:1").Return("", errors.New("err : %s", fmt.Errorf("no data in cache")))
+	// the load is shared by concurrent requests, it runs on a context not canceled with the first one
+	repoMoc.On("FindRecord", mock.Anything, "1").Return(testUserResp, nil)
+	cacheMoc.On("Set", mock.Anything, "user:1", mock.Anything, mock.Anything).Return(nil)
 
 	// When
 	testContrlr.FindUser(contextMoc)
@@ -54,6 +57,8 @@ func TestController_FindUser_Success(t *testing.T) {
 func TestController_FindUser_NoRecordsErr(t *testing.T) {
 	repoMoc := new(mocks.DBRepo)
 	contextMoc := new(mocks.Context)
+	// no request-scoped logger, the controller logs through its own
+	contextMoc.On("Value", mock.Anything).Return(nil)
 	cacheMoc := new(mocks.CacheHandler)
 
 	contextMoc.On("Param", "id").Return("9999")
@@ -64,11 +69,13 @@ func TestController_FindUser_NoRecordsErr(t *testing.T) {
 	contextMoc.On("JSON", http.StatusNotFound, respErr)
 
 	testService := userservice.New(repoMoc, logger.New())
-	testImageService := imageservice.New(nil, nil, logger.New())
-	testContrlr := New(cacheMoc, testService, testImageService, logger.New())
+	testImageService := imageservice.New(nil, nil, &config.Env{}, logger.New())
+	testContrlr := New(caching.NewReadThrough(cacheMoc, config.Defaults(config.ModeService), logger.New()), testService, testImageService, logger.New())
 
-	cacheMoc.On("Get", contextMoc, "9999").Return("", fmt.Errorf("no data in cache"))
-	repoMoc.On("FindRecord", "9999").Return(nil, repoFindErr)
+	cacheMoc.On("Get", contextMoc, "user:9999").Return("", fmt.Errorf("no data in cache"))
+	repoMoc.On("FindRecord", mock.Anything, "9999").Return(nil, repoFindErr)
+	// not found is cached too
+	cacheMoc.On("Set", mock.Anything, "user:9999", mock.Anything, mock.Anything).Return(nil)
 
 	// When
 	testContrlr.FindUser(contextMoc)
@@ -79,11 +86,14 @@ func TestController_FindUser_NoRecordsErr(t *testing.T) {
 		1,
 	)
 	repoMoc.AssertExpectations(t)
+	cacheMoc.AssertExpectations(t)
 }
 
 func TestController_FindUser_RegexBadReq(t *testing.T) {
 	repoMoc := new(mocks.DBRepo)
 	contextMoc := new(mocks.Context)
+	// no request-scoped logger, the controller logs through its own
+	contextMoc.On("Value", mock.Anything).Return(nil)
 	cacheMoc := new(mocks.CacheHandler)
 
 	// param doesn't match regex
@@ -94,8 +104,8 @@ func TestController_FindUser_RegexBadReq(t *testing.T) {
 	contextMoc.On("JSON", http.StatusBadRequest, respErr)
 
 	testService := userservice.New(repoMoc, logger.New())
-	testImageService := imageservice.New(nil, nil, logger.New())
-	testContrlr := New(cacheMoc, testService, testImageService, logger.New())
+	testImageService := imageservice.New(nil, nil, &config.Env{}, logger.New())
+	testContrlr := New(caching.NewReadThrough(cacheMoc, config.Defaults(config.ModeService), logger.New()), testService, testImageService, logger.New())
 
 	// When
 	testContrlr.FindUser(contextMoc)
@@ -111,6 +121,8 @@ func TestController_FindUser_RegexBadReq(t *testing.T) {
 func TestController_FindUser_RepoErr(t *testing.T) {
 	repoMoc := new(mocks.DBRepo)
 	contextMoc := new(mocks.Context)
+	// no request-scoped logger, the controller logs through its own
+	contextMoc.On("Value", mock.Anything).Return(nil)
 	cacheMoc := new(mocks.CacheHandler)
 
 	contextMoc.On("Param", "id").Return("1")
@@ -121,11 +133,11 @@ func TestController_FindUser_RepoErr(t *testing.T) {
 	contextMoc.On("JSON", http.StatusInternalServerError, respErr)
 
 	testService := userservice.New(repoMoc, logger.New())
-	testImageService := imageservice.New(nil, nil, logger.New())
-	testContrlr := New(cacheMoc, testService, testImageService, logger.New())
+	testImageService := imageservice.New(nil, nil, &config.Env{}, logger.New())
+	testContrlr := New(caching.NewReadThrough(cacheMoc, config.Defaults(config.ModeService), logger.New()), testService, testImageService, logger.New())
 
-	cacheMoc.On("Get", contextMoc, "1").Return("", fmt.Errorf("no data in cache"))
-	repoMoc.On("FindRecord", "1").Return(nil, repoErr)
+	cacheMoc.On("Get", contextMoc, "user:1").Return("", fmt.Errorf("no data in cache"))
+	repoMoc.On("FindRecord", mock.Anything, "1").Return(nil, repoErr)
 
 	// When
 	testContrlr.FindUser(contextMoc)
@@ -9,4 +9,5 @@ import (
 
 var Wired = wire.NewSet(
 	New,
+	NewHealthController,
 )
@@ -17,11 +17,17 @@ import (
 )
 
 const (
-	UserImageTable = "user-images"
-	HashKey        = "UserID"
-	RangeKey       = "ImageID"
-	IndexRangeKey  = "TakenAt"
-	SecondaryIndex = "UserIDTakenAtIndex"
+	UserImageTable      = "user-images"
+	UserAlbumTable      = "user-albums"
+	UserImageShareTable = "user-image-shares"
+	HashKey             = "UserID"
+	RangeKey            = "ImageID"
+	IndexRangeKey       = "TakenAt"
+	AlbumRangeKey       = "AlbumID"
+	ShareHashKey        = "ShareID"
+	ShareOwnerKey       = "OwnerID"
+	ShareOwnerIndex     = "OwnerIDImageIDIndex"
+	SecondaryIndex      = "UserIDTakenAtIndex"
 )
 
 type (
@@ -56,6 +62,14 @@ func (tc *DynamoDBSetup) initialize() {
 	if err != nil {
 		panic(err)
 	}
+	err = tc.createAlbumTable()
+	if err != nil {
+		panic(err)
+	}
+	err = tc.createShareTable()
+	if err != nil {
+		panic(err)
+	}
 }
 
 func (tc *DynamoDBSetup) createDynamoDBContainer(ctx context.Context) error {
@@ -135,6 +149,80 @@ func (tc *DynamoDBSetup) createTable() error {
 	return nil
 }
 
+func (tc *DynamoDBSetup) createAlbumTable() error {
+	_, err := tc.Client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String(UserAlbumTable),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String(HashKey),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String(AlbumRangeKey),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String(HashKey),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String(AlbumRangeKey),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create album table: %w", err)
+	}
+
+	return nil
+}
+
+func (tc *DynamoDBSetup) createShareTable() error {
+	_, err := tc.Client.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String(UserImageShareTable),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String(ShareHashKey),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String(ShareHashKey),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String(ShareOwnerKey),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String(RangeKey),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(ShareOwnerIndex),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String(ShareOwnerKey), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String(RangeKey), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create share table: %w", err)
+	}
+
+	return nil
+}
+
 func (r *dynamoDBResolver) ResolveEndpoint(ctx context.Context, params dynamodb.EndpointParameters) (smithyendpoints.Endpoint, error) {
 	return smithyendpoints.Endpoint{
 		URI: url.URL{Host: r.HostPort, Scheme: "http"},
@@ -0,0 +1,62 @@
+package integrationtest
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+type PostgresSetup struct {
+	container  *postgres.PostgresContainer
+	ConnString string
+}
+
+func NewPostgresSetup() *PostgresSetup {
+	setup := &PostgresSetup{}
+	setup.initialize()
+	return setup
+}
+
+func (tc *PostgresSetup) initialize() {
+	ctx := context.Background()
+	tc.createPostgresContainer(ctx)
+}
+
+func (tc *PostgresSetup) createPostgresContainer(ctx context.Context) {
+	log.Printf("starting test container")
+	postgresContainer, err := postgres.Run(ctx,
+		"postgres:16-alpine",
+		postgres.WithDatabase("usersdb"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("password"),
+		testcontainers.WithWaitStrategy(
+			// postgres restarts once after the init scripts ran
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(time.Minute)),
+	)
+	if err != nil {
+		log.Printf("failed to start container: %s", err)
+		return
+	}
+
+	connString, err := postgresContainer.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		log.Printf("failed to get connection string : %s", err)
+		return
+	}
+
+	tc.container = postgresContainer
+	tc.ConnString = connString
+	log.Printf("test container started")
+}
+
+func (tc *PostgresSetup) Stop() {
+	if err := testcontainers.TerminateContainer(tc.container); err != nil {
+		log.Printf("failed to terminate container: %s", err)
+	}
+}
@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	Idempotency struct {
+		store        caching.IdempotencyStore
+		keyTTL       time.Duration
+		lockTTL      time.Duration
+		maxBodyBytes int64
+		// storeTimeout bounds keeping a response or releasing a key after the client is gone
+		storeTimeout time.Duration
+		log          *logger.Logger
+	}
+
+	// storedResponse is kept under the Idempotency-Key, without a Status while the first request still runs
+	storedResponse struct {
+		Fingerprint string `json:"fingerprint"`
+		Status      int    `json:"status,omitempty"`
+		ContentType string `json:"contentType,omitempty"`
+		Body        []byte `json:"body,omitempty"`
+	}
+
+	// recordingWriter keeps a copy of the response body written through it
+	recordingWriter struct {
+		gin.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+// maxIdempotencyKeyLength bounds the keys clients may send, uuids and the like fit easily
+const maxIdempotencyKeyLength = 255
+
+func NewIdempotency(store caching.IdempotencyStore, env *config.Env, l *logger.Logger) Idempotency {
+	return Idempotency{
+		store:        store,
+		keyTTL:       env.IdempotencyKeyTTL,
+		lockTTL:      env.IdempotencyLockTTL,
+		maxBodyBytes: env.MaxUploadBytes,
+		storeTimeout: env.RedisTimeout,
+		log:          l,
+	}
+}
+
+// Idempotent runs a request sent with an Idempotency-Key once and replays its response to retries with the same key
+// and payload, marked by the Idempotent-Replayed header. A retry while the first request runs gets 409, the key sent
+// with another payload 422, a body over the upload limit 413. Failed requests created nothing, their response is not kept and the key can be used again.
+// Requests without the header, or while the cache can not be reached, are run as is.
+func (i *Idempotency) Idempotent() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(config.HeaderIdempotencyKey)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			e := fmt.Errorf("header %s is longer than %d characters", config.HeaderIdempotencyKey, maxIdempotencyKeyLength)
+			i.abort(ctx, http.StatusBadRequest, errors.New(errors.ErrCodeBadRequest, e))
+			return
+		}
+
+		// the body is read whole to fingerprint it, it must fit in memory
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, i.maxBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if stderrors.As(err, &tooLarge) {
+			e := fmt.Errorf("body is larger than %d bytes", tooLarge.Limit)
+			i.abort(ctx, http.StatusRequestEntityTooLarge, errors.New(errors.ErrCodePayloadTooLarge, e))
+			return
+		}
+		if err != nil {
+			i.abort(ctx, http.StatusBadRequest, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("err reading body :: %v", err)))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		cacheKey := caching.IdempotencyKeyPrefix + ctx.Request.Method + ctx.FullPath() + ":" + rateLimitSubject(ctx) + ":" + key
+		fingerprint := requestFingerprint(ctx.GetHeader(config.HeaderContentType), body)
+		pending, _ := json.Marshal(storedResponse{Fingerprint: fingerprint})
+
+		locked, err := i.store.SetNX(ctx, cacheKey, string(pending), i.lockTTL)
+		if err != nil {
+			i.log.For(ctx).Warnf("could not check %s, running the request as is :: %v", config.HeaderIdempotencyKey, err)
+			ctx.Next()
+			return
+		}
+		if !locked {
+			i.replay(ctx, cacheKey, fingerprint)
+			return
+		}
+
+		i.run(ctx, cacheKey, fingerprint)
+	}
+}
+
+// run handles the request holding the key, keeping its response if it succeeded
+func (i *Idempotency) run(ctx *gin.Context, cacheKey, fingerprint string) {
+	w := &recordingWriter{ResponseWriter: ctx.Writer}
+	ctx.Writer = w
+	kept := false
+	defer func() {
+		// a panic or an error releases the key, so the client can retry
+		if !kept {
+			storeCtx, cancel := i.storeContext(ctx)
+			defer cancel()
+			if err := i.store.Delete(storeCtx, cacheKey); err != nil {
+				i.log.For(ctx).Warnf("err releasing %s :: %s", config.HeaderIdempotencyKey, err)
+			}
+		}
+	}()
+
+	ctx.Next()
+
+	if w.Status() >= http.StatusBadRequest {
+		return
+	}
+	resp, _ := json.Marshal(storedResponse{
+		Fingerprint: fingerprint,
+		Status:      w.Status(),
+		ContentType: w.Header().Get(config.HeaderContentType),
+		Body:        w.body.Bytes(),
+	})
+	storeCtx, cancel := i.storeContext(ctx)
+	defer cancel()
+	if err := i.store.Set(storeCtx, cacheKey, string(resp), i.keyTTL); err != nil {
+		i.log.For(ctx).Warnf("err keeping the response of %s :: %s", config.HeaderIdempotencyKey, err)
+		return
+	}
+	kept = true
+}
+
+// storeContext outlives the request for settling its key. A client timing out and hanging up is what the
+// key is for, its retry must find the response kept or the key released.
+func (i *Idempotency) storeContext(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), i.storeTimeout)
+}
+
+// replay answers a request whose key is taken with the response kept for it
+func (i *Idempotency) replay(ctx *gin.Context, cacheKey, fingerprint string) {
+	var stored storedResponse
+	value, err := i.store.Get(ctx, cacheKey)
+	if err == nil {
+		err = json.Unmarshal([]byte(value), &stored)
+	}
+	switch {
+	case err != nil:
+		// released or expired since, the next retry of the client takes it
+		e := fmt.Errorf("request with the same %s was just released, retry later", config.HeaderIdempotencyKey)
+		i.abort(ctx, http.StatusConflict, errors.New(errors.ErrCodeConflict, e))
+	case stored.Fingerprint != fingerprint:
+		e := fmt.Errorf("%s was already used with a different request", config.HeaderIdempotencyKey)
+		i.abort(ctx, http.StatusUnprocessableEntity, errors.New(errors.ErrCodeIdempotencyKeyReused, e))
+	case stored.Status == 0:
+		e := fmt.Errorf("request with the same %s is still being processed", config.HeaderIdempotencyKey)
+		i.abort(ctx, http.StatusConflict, errors.New(errors.ErrCodeConflict, e))
+	default:
+		ctx.Header(config.HeaderIdempotentReplayed, "true")
+		ctx.Data(stored.Status, stored.ContentType, stored.Body)
+		ctx.Abort()
+	}
+}
+
+func (i *Idempotency) abort(ctx *gin.Context, code int, err errors.Error) {
+	i.log.For(ctx).Warnf("err :: %s", err)
+	_ = ctx.Error(err)
+	ctx.AbortWithStatusJSON(code, err)
+}
+
+// requestFingerprint hashes the payload of a request. Multipart bodies are hashed by their parts,
+// clients pick a new boundary for every retry
+func requestFingerprint(contentType string, body []byte) string {
+	h := sha256.New()
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		if hashParts(h, multipart.NewReader(bytes.NewReader(body), params["boundary"])) == nil {
+			return hex.EncodeToString(h.Sum(nil))
+		}
+		// not parseable, the handler will reject it, the raw body tells retries apart
+		h.Reset()
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashParts(h hash.Hash, r *multipart.Reader) error {
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%q;%q;%q\n", p.FormName(), p.FileName(), p.Header.Get(config.HeaderContentType))
+		if _, err := io.Copy(h, p); err != nil {
+			return err
+		}
+		h.Write([]byte{0})
+	}
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,225 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	idempotentServer struct {
+		engine  *gin.Engine
+		calls   int
+		status  int
+		started chan struct{}
+		release chan struct{}
+		// hangUp is called by the handler before it answers, like a client disconnecting
+		hangUp func()
+	}
+
+	// redisLikeStore refuses calls on a done context, like go-redis does
+	redisLikeStore struct {
+		caching.IdempotencyStore
+	}
+)
+
+func (s redisLikeStore) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.IdempotencyStore.Get(ctx, key)
+}
+
+func (s redisLikeStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.IdempotencyStore.Set(ctx, key, value, ttl)
+}
+
+func (s redisLikeStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.IdempotencyStore.Delete(ctx, key)
+}
+
+func (s redisLikeStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return s.IdempotencyStore.SetNX(ctx, key, value, ttl)
+}
+
+func newIdempotentServer(t *testing.T) *idempotentServer {
+	t.Helper()
+	return newIdempotentServerWith(t, config.Defaults(config.ModeStandalone))
+}
+
+func newIdempotentServerWith(t *testing.T, env *config.Env) *idempotentServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	idem := NewIdempotency(redisLikeStore{caching.NewMemoryCache(logger.New())}, env, logger.New())
+	s := &idempotentServer{engine: gin.New(), status: http.StatusAccepted}
+	// like the server, the gin context is done with the request
+	s.engine.ContextWithFallback = true
+	s.engine.POST("/users", idem.Idempotent(), func(c *gin.Context) {
+		s.calls++
+		if s.started != nil {
+			s.started <- struct{}{}
+			<-s.release
+		}
+		if s.hangUp != nil {
+			s.hangUp()
+		}
+		c.JSON(s.status, gin.H{"call": s.calls})
+	})
+	return s
+}
+
+func (s *idempotentServer) post(key, contentType string, body []byte) *httptest.ResponseRecorder {
+	return s.postContext(context.Background(), key, contentType, body)
+}
+
+func (s *idempotentServer) postContext(ctx context.Context, key, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/users", bytes.NewReader(body))
+	req.Header.Set(config.HeaderUserID, "42")
+	req.Header.Set(config.HeaderContentType, contentType)
+	if key != "" {
+		req.Header.Set(config.HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	s.engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotent_ReplaysTheFirstResponse(t *testing.T) {
+	s := newIdempotentServer(t)
+	body := []byte(`{"email":"a@b.c"}`)
+
+	first := s.post("key-1", "application/json", body)
+	retry := s.post("key-1", "application/json", body)
+
+	assert.Equal(t, 1, s.calls)
+	assert.Equal(t, http.StatusAccepted, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(config.HeaderIdempotentReplayed))
+	assert.Empty(t, first.Header().Get(config.HeaderIdempotentReplayed))
+
+	s.post("", "application/json", body)
+	s.post("", "application/json", body)
+	assert.Equal(t, 3, s.calls, "requests without a key run every time")
+}
+
+func TestIdempotent_KeepsTheResponseOfAClientThatHungUp(t *testing.T) {
+	for _, status := range []int{http.StatusAccepted, http.StatusInternalServerError} {
+		s := newIdempotentServer(t)
+		s.status = status
+		body := []byte(`{"email":"a@b.c"}`)
+		ctx, cancel := context.WithCancel(context.Background())
+		s.hangUp = cancel
+
+		s.postContext(ctx, "key-1", "application/json", body)
+		s.hangUp = nil
+		s.status = http.StatusAccepted
+		retry := s.post("key-1", "application/json", body)
+
+		assert.Equal(t, http.StatusAccepted, retry.Code, status)
+		if status < http.StatusBadRequest {
+			assert.Equal(t, "true", retry.Header().Get(config.HeaderIdempotentReplayed), "the response was kept")
+			assert.Equal(t, 1, s.calls)
+		} else {
+			assert.Equal(t, 2, s.calls, "the key was released")
+		}
+	}
+}
+
+func TestIdempotent_RejectsKeyReusedWithAnotherPayload(t *testing.T) {
+	s := newIdempotentServer(t)
+
+	s.post("key-1", "application/json", []byte(`{"email":"a@b.c"}`))
+	rec := s.post("key-1", "application/json", []byte(`{"email":"x@y.z"}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "IdempotencyKeyReused")
+	assert.Equal(t, 1, s.calls)
+}
+
+func TestIdempotent_RejectsConcurrentDuplicate(t *testing.T) {
+	s := newIdempotentServer(t)
+	s.started, s.release = make(chan struct{}), make(chan struct{})
+	body := []byte(`{"email":"a@b.c"}`)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- s.post("key-1", "application/json", body) }()
+	<-s.started
+
+	rec := s.post("key-1", "application/json", body)
+	close(s.release)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Conflict")
+	assert.Equal(t, http.StatusAccepted, (<-done).Code)
+}
+
+func TestIdempotent_ReleasesKeyOnError(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusInternalServerError} {
+		s := newIdempotentServer(t)
+		s.status = status
+		body := []byte(`{"email":"a@b.c"}`)
+
+		s.post("key-1", "application/json", body)
+		s.status = http.StatusAccepted
+		rec := s.post("key-1", "application/json", body)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code, status)
+		assert.Equal(t, 2, s.calls, status)
+	}
+}
+
+func TestIdempotent_RejectsBodyOverTheUploadLimit(t *testing.T) {
+	env := config.Defaults(config.ModeStandalone)
+	env.MaxUploadBytes = 20
+	s := newIdempotentServerWith(t, env)
+
+	rec := s.post("key-1", "application/json", []byte(`{"email":"someone@example.com"}`))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "PayloadTooLarge")
+	assert.Equal(t, 0, s.calls)
+
+	assert.Equal(t, http.StatusAccepted, s.post("key-2", "application/json", []byte(`{"email":"a@b.c"}`)).Code)
+}
+
+func TestIdempotent_MultipartIgnoresTheBoundary(t *testing.T) {
+	s := newIdempotentServer(t)
+	upload := func(file string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		fw, err := w.CreateFormFile("file", "cat.png")
+		require.NoError(t, err)
+		_, _ = fw.Write([]byte(file))
+		require.NoError(t, w.Close())
+		return s.post("key-1", w.FormDataContentType(), buf.Bytes())
+	}
+
+	upload("image bytes")
+	retry := upload("image bytes")
+	other := upload("other bytes")
+
+	assert.Equal(t, http.StatusAccepted, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(config.HeaderIdempotentReplayed))
+	assert.Equal(t, http.StatusUnprocessableEntity, other.Code)
+	assert.Equal(t, 1, s.calls)
+}
@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	RateLimiter struct {
+		limiter caching.RateLimiter
+		enabled bool
+		limits  map[string]config.RateLimit
+		log     *logger.Logger
+	}
+)
+
+// ipLimitedGroups are served without ValidateRequest, the x-user-id header is not checked there
+// and any client could send another one with every request
+var ipLimitedGroups = map[string]bool{config.RateLimitGroupPublic: true}
+
+func NewRateLimiter(rl caching.RateLimiter, env *config.Env, l *logger.Logger) RateLimiter {
+	return RateLimiter{
+		limiter: rl,
+		enabled: env.RateLimitEnabled,
+		limits: map[string]config.RateLimit{
+			config.RateLimitGroupUsers:   env.RateLimitUsers,
+			config.RateLimitGroupImages:  env.RateLimitImages,
+			config.RateLimitGroupUploads: env.RateLimitUploads,
+			config.RateLimitGroupPublic:  env.RateLimitPublic,
+		},
+		log: l,
+	}
+}
+
+// Limit counts the request against the limit of the route group, per user of the x-user-id header
+// or per client ip without one, and answers 429 once it is reached. The public group is counted per client ip only. The RateLimit headers tell the client
+// its limit, the requests left and the seconds until the count goes down.
+// If the counters can not be reached the request is let through.
+func (r *RateLimiter) Limit(group string) gin.HandlerFunc {
+	limit, ok := r.limits[group]
+	if !r.enabled || !ok {
+		return func(ctx *gin.Context) { ctx.Next() }
+	}
+	policy := fmt.Sprintf("%d;w=%d", limit.Requests, int(math.Ceil(limit.Window.Seconds())))
+	subject := rateLimitSubject
+	if ipLimitedGroups[group] {
+		subject = clientIPSubject
+	}
+
+	return func(ctx *gin.Context) {
+		a, err := r.limiter.Allow(ctx, group+":"+subject(ctx), limit.Requests, limit.Window)
+		if err != nil {
+			r.log.For(ctx).Warnf("could not count rate limit %s, letting the request through :: %v", group, err)
+			ctx.Next()
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(a.Reset.Seconds())))
+		h := ctx.Writer.Header()
+		h.Set(config.HeaderRateLimitPolicy, policy)
+		h.Set(config.HeaderRateLimitLimit, strconv.Itoa(limit.Requests))
+		h.Set(config.HeaderRateLimitRemaining, strconv.Itoa(a.Remaining))
+		h.Set(config.HeaderRateLimitReset, reset)
+
+		if !a.Allowed {
+			h.Set(config.HeaderRetryAfter, reset)
+			e := fmt.Errorf("rate limit %s of %d requests per %s exceeded", group, limit.Requests, limit.Window)
+			r.log.For(ctx).Warnf("err :: %s", e)
+			_ = ctx.Error(e)
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, errors.New(errors.ErrCodeTooManyRequests, e))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// rateLimitSubject is who the request is counted for, the user or else the client ip
+func rateLimitSubject(ctx *gin.Context) string {
+	if uID := ctx.GetHeader(config.HeaderUserID); uID != "" {
+		return "user:" + uID
+	}
+	return clientIPSubject(ctx)
+}
+
+func clientIPSubject(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
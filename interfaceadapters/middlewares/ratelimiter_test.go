@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/infrastructure/caching"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, int, time.Duration) (caching.Allowance, error) {
+	return caching.Allowance{}, stderrors.New("connection refused")
+}
+
+func serveLimited(rl caching.RateLimiter, env *config.Env) func(userID, ip string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	lim := NewRateLimiter(rl, env, logger.New())
+	e := gin.New()
+	e.GET("/users/:id", lim.Limit(config.RateLimitGroupUsers), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return func(userID, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.RemoteAddr = ip + ":1234"
+		if userID != "" {
+			req.Header.Set(config.HeaderUserID, userID)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+}
+
+func limitedEnv(requests int) *config.Env {
+	env := config.Defaults(config.ModeStandalone)
+	env.RateLimitUsers = config.RateLimit{Requests: requests, Window: time.Minute}
+	return env
+}
+
+func TestLimit_CountsPerUserAndAnswersTooManyRequests(t *testing.T) {
+	serve := serveLimited(caching.NewMemoryCache(logger.New()), limitedEnv(2))
+
+	rec := serve("42", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(config.HeaderRateLimitLimit))
+	assert.Equal(t, "1", rec.Header().Get(config.HeaderRateLimitRemaining))
+	assert.Equal(t, "2;w=60", rec.Header().Get(config.HeaderRateLimitPolicy))
+	assert.NotEmpty(t, rec.Header().Get(config.HeaderRateLimitReset))
+
+	assert.Equal(t, http.StatusOK, serve("42", "10.0.0.2").Code, "the user is counted, not the ip")
+	rec = serve("42", "10.0.0.3")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(config.HeaderRateLimitRemaining))
+	assert.NotEmpty(t, rec.Header().Get(config.HeaderRetryAfter))
+	assert.Contains(t, rec.Body.String(), "TooManyRequests")
+
+	assert.Equal(t, http.StatusOK, serve("43", "10.0.0.3").Code, "other users have limits of their own")
+}
+
+func TestLimit_CountsPerIPWithoutUser(t *testing.T) {
+	serve := serveLimited(caching.NewMemoryCache(logger.New()), limitedEnv(1))
+
+	assert.Equal(t, http.StatusOK, serve("", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, serve("", "10.0.0.2").Code)
+}
+
+func TestLimit_CountsPublicPerIPWhateverTheUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := config.Defaults(config.ModeStandalone)
+	env.RateLimitPublic = config.RateLimit{Requests: 1, Window: time.Minute}
+	lim := NewRateLimiter(caching.NewMemoryCache(logger.New()), env, logger.New())
+	e := gin.New()
+	e.GET("/share/:token", lim.Limit(config.RateLimitGroupPublic), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(userID string) int {
+		req := httptest.NewRequest(http.MethodGet, "/share/abc", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set(config.HeaderUserID, userID)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("42"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("43"), "the header is not checked on public routes")
+}
+
+func TestLimit_LetsThroughWhenDisabledOrFailing(t *testing.T) {
+	disabled := limitedEnv(1)
+	disabled.RateLimitEnabled = false
+	serve := serveLimited(caching.NewMemoryCache(logger.New()), disabled)
+	serve("42", "10.0.0.1")
+	rec := serve("42", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(config.HeaderRateLimitLimit))
+
+	serve = serveLimited(failingLimiter{}, limitedEnv(1))
+	assert.Equal(t, http.StatusOK, serve("42", "10.0.0.1").Code)
+}
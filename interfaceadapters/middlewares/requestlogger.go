@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	RequestLogger struct {
+		log *logger.Logger
+	}
+)
+
+// requestIDRegExp limits the request ids taken from callers to ones safe to log and echo
+var requestIDRegExp = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+func NewRequestLogger(l *logger.Logger) RequestLogger {
+	return RequestLogger{log: l}
+}
+
+// ScopeLogger ties the logs of a request together. It takes the request id of the caller or
+// generates one, echoes it in the response and puts a logger with the request id, user id and
+// route into the request context, picked up by logger.For down the stack.
+func (rl *RequestLogger) ScopeLogger() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(config.HeaderRequestID)
+		if !requestIDRegExp.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		ctx.Header(config.HeaderRequestID, requestID)
+
+		scoped := rl.log.WithTrace(ctx.Request.Context()).With("request_id", requestID, "route", ctx.FullPath())
+		if userID := ctx.GetHeader(config.HeaderUserID); userID != "" {
+			scoped = scoped.With("user_id", userID)
+		}
+		ctx.Request = ctx.Request.WithContext(logger.NewContext(ctx.Request.Context(), scoped))
+
+		ctx.Next()
+	}
+}
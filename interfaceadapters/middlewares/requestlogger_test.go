@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func serveWithRequestLogger(t *testing.T, req *http.Request) (*httptest.ResponseRecorder, *observer.ObservedLogs) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zap.DebugLevel)
+	rl := NewRequestLogger(&logger.Logger{SugaredLogger: zap.New(core).Sugar()})
+
+	e := gin.New()
+	e.ContextWithFallback = true
+	e.Use(rl.ScopeLogger())
+	e.GET("/users/:id", func(c *gin.Context) {
+		// a logger without fields, as services hold, logs through the scoped one
+		logger.New().For(c).Info("deep in the stack")
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec, logs
+}
+
+func TestScopeLogger_KeepsRequestIDOfCaller(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(config.HeaderRequestID, "abc-123")
+	req.Header.Set(config.HeaderUserID, "42")
+
+	rec, logs := serveWithRequestLogger(t, req)
+
+	assert.Equal(t, "abc-123", rec.Header().Get(config.HeaderRequestID))
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "abc-123", fields["request_id"])
+	assert.Equal(t, "42", fields["user_id"])
+	assert.Equal(t, "/users/:id", fields["route"])
+}
+
+func TestScopeLogger_GeneratesRequestID(t *testing.T) {
+	for name, header := range map[string]string{"missing": "", "unsafe": "a\nforged log line"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			req.Header.Set(config.HeaderRequestID, header)
+
+			rec, logs := serveWithRequestLogger(t, req)
+
+			requestID := rec.Header().Get(config.HeaderRequestID)
+			assert.Len(t, requestID, 36)
+			require.Equal(t, 1, logs.Len())
+			assert.Equal(t, requestID, logs.All()[0].ContextMap()["request_id"])
+			assert.NotContains(t, logs.All()[0].ContextMap(), "user_id")
+		})
+	}
+}
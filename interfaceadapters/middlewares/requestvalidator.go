@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 
@@ -12,12 +13,13 @@ import (
 
 type (
 	Validator struct {
-		log *logger.Logger
+		adminToken string
+		log        *logger.Logger
 	}
 )
 
-func New(l *logger.Logger) Validator {
-	return Validator{log: l}
+func New(env *config.Env, l *logger.Logger) Validator {
+	return Validator{adminToken: env.AdminToken, log: l}
 }
 
 func (v *Validator) ValidateRequest() gin.HandlerFunc {
@@ -26,9 +28,25 @@ func (v *Validator) ValidateRequest() gin.HandlerFunc {
 			ctx.Next()
 		} else {
 			e := fmt.Errorf("required header %s not available", config.HeaderIDToken)
-			v.log.Warnf("err :: %s", e)
+			v.log.For(ctx).Warnf("err :: %s", e)
 			_ = ctx.Error(e)
 			ctx.AbortWithStatusJSON(http.StatusForbidden, errors.New(errors.ErrCodeInvalidUserIDHeader, e))
 		}
 	}
 }
+
+// ValidateAdmin lets requests carrying the admin token of the config through,
+// without a configured token the admin endpoints are closed
+func (v *Validator) ValidateAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := ctx.GetHeader(config.HeaderAdminToken)
+		if v.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(v.adminToken)) == 1 {
+			ctx.Next()
+		} else {
+			e := fmt.Errorf("required header %s missing or wrong", config.HeaderAdminToken)
+			v.log.For(ctx).Warnf("err :: %s", e)
+			_ = ctx.Error(e)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errors.New(errors.ErrCodeUnauthorized, e))
+		}
+	}
+}
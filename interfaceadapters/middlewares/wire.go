@@ -9,4 +9,7 @@ import (
 
 var Wired = wire.NewSet(
 	New,
+	NewRequestLogger,
+	NewRateLimiter,
+	NewIdempotency,
 )
@@ -0,0 +1,241 @@
+package dynamorepo
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"golang.org/x/sync/errgroup"
+)
+
+type (
+	AlbumHandler interface {
+		CreateAlbum(ctx context.Context, a *models.Album) error
+		GetAlbum(ctx context.Context, uID, albumID string) (*models.Album, error)
+		ListAlbums(ctx context.Context, uID string) ([]models.Album, error)
+		RenameAlbum(ctx context.Context, uID, albumID, name string) (*models.Album, error)
+		DeleteAlbum(ctx context.Context, uID, albumID string) error
+	}
+)
+
+const (
+	AlbumRangeKey = "AlbumID"
+)
+
+func (d *DynamoDBRepo) CreateAlbum(ctx context.Context, req *models.Album) error {
+	item, err := attributevalue.MarshalMap(req)
+	if err != nil {
+		d.Log.For(ctx).Error("error marshaling input", err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error marshaling input"))
+	}
+
+	_, err = d.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &d.AlbumTableName,
+		Item:      item,
+	})
+	if err != nil {
+		d.Log.For(ctx).Errorf("error persisting album %s of user %s to db %v", req.AlbumID, req.UserID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error persisting album data"))
+	}
+
+	return nil
+}
+
+func (d *DynamoDBRepo) GetAlbum(ctx context.Context, uID, albumID string) (*models.Album, error) {
+	result, err := d.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &d.AlbumTableName,
+		Key:       albumKey(uID, albumID),
+	})
+	if err != nil {
+		d.Log.For(ctx).Error("error querying db", err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+	}
+	if result.Item == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("album not found"))
+	}
+
+	var album models.Album
+	err = attributevalue.UnmarshalMap(result.Item, &album)
+	if err != nil {
+		d.Log.For(ctx).Error("error unmarshaling db response", err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+	}
+
+	return &album, nil
+}
+
+func (d *DynamoDBRepo) ListAlbums(ctx context.Context, uID string) ([]models.Album, error) {
+	var lastEvaluatedKey map[string]types.AttributeValue
+	albums := make([]models.Album, 0)
+
+	for {
+		result, err := d.Client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              &d.AlbumTableName,
+			KeyConditionExpression: aws.String("UserID = :uID"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":uID": &types.AttributeValueMemberS{Value: uID},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		})
+		if err != nil {
+			d.Log.For(ctx).Error("error querying db", err)
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+		}
+
+		var albumResults []models.Album
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &albumResults)
+		if err != nil {
+			d.Log.For(ctx).Error("error unmarshaling db response", err)
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+		}
+		albums = append(albums, albumResults...)
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return albums, nil
+}
+
+func (d *DynamoDBRepo) RenameAlbum(ctx context.Context, uID, albumID, name string) (*models.Album, error) {
+	result, err := d.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &d.AlbumTableName,
+		Key:                 albumKey(uID, albumID),
+		ConditionExpression: aws.String("attribute_exists(AlbumID)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":name":      &types.AttributeValueMemberS{Value: name},
+			":updatedAt": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
+		},
+		UpdateExpression: aws.String("SET #name = :name, UpdatedAt = :updatedAt"),
+		// Name is a reserved word in dynamoDB expressions
+		ExpressionAttributeNames: map[string]string{"#name": "Name"},
+		ReturnValues:             types.ReturnValueAllNew,
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("album not found"))
+		}
+		d.Log.For(ctx).Errorf("error renaming album %s of user %s. error %v", albumID, uID, err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing album"))
+	}
+
+	var album models.Album
+	err = attributevalue.UnmarshalMap(result.Attributes, &album)
+	if err != nil {
+		d.Log.For(ctx).Error("error unmarshaling db response", err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+	}
+
+	return &album, nil
+}
+
+// AlbumUpdateConcurrency bounds the images updated at once when an album is deleted
+const AlbumUpdateConcurrency = 8
+
+// DeleteAlbum removes the album from all its images before deleting the album itself,
+// so a failure midway leaves the album in place to be deleted again
+func (d *DynamoDBRepo) DeleteAlbum(ctx context.Context, uID, albumID string) error {
+	if _, err := d.GetAlbum(ctx, uID, albumID); err != nil {
+		return err
+	}
+
+	imageResults, err := d.queryAllItems(ctx, models.PaginatedInput{UserID: uID, AlbumID: albumID})
+	if err != nil {
+		return err
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(AlbumUpdateConcurrency)
+	for _, item := range imageResults {
+		g.Go(func() error {
+			return d.RemoveImageFromAlbum(gctx, item.UserID, item.ImageID, albumID)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		d.Log.For(ctx).Errorf("error removing images of user %s from album %s in DB. error :: %v", uID, albumID, err)
+		return err
+	}
+
+	_, err = d.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &d.AlbumTableName,
+		Key:       albumKey(uID, albumID),
+	})
+	if err != nil {
+		d.Log.For(ctx).Errorf("error deleting album %s of user %s. error %v", albumID, uID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing album"))
+	}
+
+	return nil
+}
+
+func (d *DynamoDBRepo) AddImageToAlbum(ctx context.Context, uID, imgID, albumID string) error {
+	return d.updateLiveImage(ctx, uID, imgID, "ADD AlbumIDs :albumID", map[string]types.AttributeValue{
+		":albumID": &types.AttributeValueMemberSS{Value: []string{albumID}},
+	})
+}
+
+func (d *DynamoDBRepo) RemoveImageFromAlbum(ctx context.Context, uID, imgID, albumID string) error {
+	return d.updateLiveImage(ctx, uID, imgID, "DELETE AlbumIDs :albumID", map[string]types.AttributeValue{
+		":albumID": &types.AttributeValueMemberSS{Value: []string{albumID}},
+	})
+}
+
+// SetImageTags replaces the tags of an image, an empty list removes all tags
+func (d *DynamoDBRepo) SetImageTags(ctx context.Context, uID, imgID string, tags []string) error {
+	if len(tags) == 0 {
+		return d.updateLiveImage(ctx, uID, imgID, "REMOVE Tags", nil)
+	}
+	return d.updateLiveImage(ctx, uID, imgID, "SET Tags = :tags", map[string]types.AttributeValue{
+		":tags": &types.AttributeValueMemberSS{Value: tags},
+	})
+}
+
+// updateLiveImage applies the update expression to an image that exists and is not soft deleted
+func (d *DynamoDBRepo) updateLiveImage(ctx context.Context, uID, imgID, expression string, values map[string]types.AttributeValue) error {
+	if values == nil {
+		values = make(map[string]types.AttributeValue)
+	}
+	values[":isDeleted"] = &types.AttributeValueMemberBOOL{Value: false}
+
+	_, err := d.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &d.TableName,
+		Key: map[string]types.AttributeValue{
+			HashKey:  &types.AttributeValueMemberS{Value: uID},
+			RangeKey: &types.AttributeValueMemberS{Value: imgID},
+		},
+		ConditionExpression:       aws.String("attribute_exists(ImageID) AND IsDeleted = :isDeleted"),
+		ExpressionAttributeValues: values,
+		UpdateExpression:          aws.String(expression),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return errors.New(errors.ErrCodeNotFound, fmt.Errorf("image not found"))
+		}
+		d.Log.For(ctx).Errorf("error updating image %s of user %s. error %v", imgID, uID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing image"))
+	}
+
+	return nil
+}
+
+func albumKey(uID, albumID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		HashKey:       &types.AttributeValueMemberS{Value: uID},
+		AlbumRangeKey: &types.AttributeValueMemberS{Value: albumID},
+	}
+}
+
+func isConditionFailed(err error) bool {
+	var ccf *types.ConditionalCheckFailedException
+	return stderrors.As(err, &ccf)
+}
@@ -0,0 +1,125 @@
+package dynamorepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *RepoTestSuite) TestShouldCreateAndRenameAlbum() {
+
+	createReq := &models.Album{
+		UserID:    "555",
+		AlbumID:   "5a2b4c6e-a10a-11ef-ba63-c689f470ad55",
+		Name:      "holidays",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	err := s.repo.CreateAlbum(context.Background(), createReq)
+	assert.Nil(s.T(), err)
+
+	album, err := s.repo.RenameAlbum(context.Background(), "555", "5a2b4c6e-a10a-11ef-ba63-c689f470ad55", "summer holidays")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "summer holidays", album.Name)
+
+	albums, err := s.repo.ListAlbums(context.Background(), "555")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, len(albums))
+	assert.Equal(s.T(), "summer holidays", albums[0].Name)
+}
+
+func (s *RepoTestSuite) TestShouldNotRenameAlbumIfNotExist() {
+
+	_, err := s.repo.RenameAlbum(context.Background(), "555", "00000000-a10a-11ef-ba63-c689f470ad55", "nothing")
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), "album not found", err.Error())
+}
+
+func (s *RepoTestSuite) TestShouldGetAlbumAndTagImagesPaginated() {
+
+	todayTime := time.Now()
+	albumID := "6a2b4c6e-a10a-11ef-ba63-c689f470ad55"
+
+	err := s.repo.CreateAlbum(context.Background(), &models.Album{UserID: "666", AlbumID: albumID, Name: "pets", CreatedAt: todayTime, UpdatedAt: todayTime})
+	assert.Nil(s.T(), err)
+
+	for i, imageID := range []string{"eeeeeee-1111111", "fffffff-2222222", "ggggggg-3333333"} {
+		err = s.repo.AddImage(context.Background(), &models.UserImage{
+			IsDeleted: false,
+			UserID:    "666",
+			ImageID:   imageID,
+			Path:      "story-image/666/" + imageID + ".jpg",
+			TakenAt:   todayTime.AddDate(0, 0, -i),
+			UpdatedAt: todayTime,
+		})
+		assert.Nil(s.T(), err)
+	}
+
+	err = s.repo.AddImageToAlbum(context.Background(), "666", "eeeeeee-1111111", albumID)
+	assert.Nil(s.T(), err)
+	err = s.repo.AddImageToAlbum(context.Background(), "666", "ggggggg-3333333", albumID)
+	assert.Nil(s.T(), err)
+	err = s.repo.SetImageTags(context.Background(), "666", "fffffff-2222222", []string{"cat", "sofa"})
+	assert.Nil(s.T(), err)
+
+	data, err := s.repo.GetAllImagesPaginated(context.Background(), models.PaginatedInput{UserID: "666", AlbumID: albumID, Limit: 10})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, len(data.UserImages))
+	assert.Equal(s.T(), "eeeeeee-1111111", data.UserImages[0].ImageID)
+	assert.Equal(s.T(), "ggggggg-3333333", data.UserImages[1].ImageID)
+
+	data, err = s.repo.GetAllImagesPaginated(context.Background(), models.PaginatedInput{UserID: "666", Tag: "cat", Limit: 10})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, len(data.UserImages))
+	assert.Equal(s.T(), "fffffff-2222222", data.UserImages[0].ImageID)
+	assert.ElementsMatch(s.T(), []string{"cat", "sofa"}, data.UserImages[0].Tags)
+
+	// deleting an image drops its album membership
+	err = s.repo.DeleteImage(context.Background(), "666", "eeeeeee-1111111")
+	assert.Nil(s.T(), err)
+
+	data, err = s.repo.GetAllImagesPaginated(context.Background(), models.PaginatedInput{UserID: "666", AlbumID: albumID, Limit: 10})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, len(data.UserImages))
+	assert.Equal(s.T(), "ggggggg-3333333", data.UserImages[0].ImageID)
+}
+
+func (s *RepoTestSuite) TestShouldDeleteAlbumAndMembership() {
+
+	todayTime := time.Now()
+	albumID := "7a2b4c6e-a10a-11ef-ba63-c689f470ad55"
+
+	err := s.repo.CreateAlbum(context.Background(), &models.Album{UserID: "888", AlbumID: albumID, Name: "trips", CreatedAt: todayTime, UpdatedAt: todayTime})
+	assert.Nil(s.T(), err)
+	err = s.repo.AddImage(context.Background(), &models.UserImage{
+		IsDeleted: false,
+		UserID:    "888",
+		ImageID:   "hhhhhhh-1111111",
+		Path:      "story-image/888/hhhhhhh-1111111.jpg",
+		TakenAt:   todayTime,
+		UpdatedAt: todayTime,
+	})
+	assert.Nil(s.T(), err)
+	err = s.repo.AddImageToAlbum(context.Background(), "888", "hhhhhhh-1111111", albumID)
+	assert.Nil(s.T(), err)
+
+	err = s.repo.DeleteAlbum(context.Background(), "888", albumID)
+	assert.Nil(s.T(), err)
+
+	_, err = s.repo.GetAlbum(context.Background(), "888", albumID)
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), "album not found", err.Error())
+
+	image, err := s.repo.GetImage(context.Background(), "888", "hhhhhhh-1111111")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 0, len(image.AlbumIDs))
+}
+
+func (s *RepoTestSuite) TestShouldNotAddDeletedImageToAlbum() {
+
+	err := s.repo.AddImageToAlbum(context.Background(), "888", "00000000-a10a-11ef-ba63-c689f470ad55", "7a2b4c6e-a10a-11ef-ba63-c689f470ad55")
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), "image not found", err.Error())
+}
@@ -0,0 +1,37 @@
+package dynamorepo_test
+
+import (
+	"testing"
+
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/integrationtest"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo/dynamorepotest"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContractTestSuite struct {
+	dynamorepotest.ContractSuite
+	dynamoSetup *integrationtest.DynamoDBSetup
+}
+
+func (s *ContractTestSuite) SetupSuite() {
+	s.dynamoSetup = integrationtest.NewDynamoDbSetup()
+	s.NewRepo = func() dynamorepo.DataHandler {
+		return &dynamorepo.DynamoDBRepo{
+			TableName:      integrationtest.UserImageTable,
+			AlbumTableName: integrationtest.UserAlbumTable,
+			ShareTableName: integrationtest.UserImageShareTable,
+			Client:         s.dynamoSetup.Client,
+			Log:            logger.New(),
+		}
+	}
+}
+
+func (s *ContractTestSuite) TearDownSuite() {
+	s.dynamoSetup.Stop()
+}
+
+func TestContractSuite(t *testing.T) {
+	suite.Run(t, new(ContractTestSuite))
+}
@@ -3,6 +3,7 @@ package dynamorepo
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -15,23 +16,36 @@ import (
 	"github.com/rahul-aut-ind/service-user/internal/awsconfig"
 	"github.com/rahul-aut-ind/service-user/internal/config"
 	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 )
 
 type (
 	DataHandler interface {
-		AddImage(p *models.UserImage) error
-		GetAllImagesPaginated(req models.PaginatedInput) (*models.UserImageResult, error)
-		GetImage(uID, imgID string) (*models.UserImage, error)
-		DeleteImage(uID, imgID string) error
-		DeleteAllImages(uID string) error
-		getAllItems(uID string) ([]models.UserImage, error)
-		softDeleteItem(p *models.UserImage) error
+		AddImage(ctx context.Context, p *models.UserImage) error
+		GetAllImagesPaginated(ctx context.Context, req models.PaginatedInput) (*models.UserImageResult, error)
+		GetImage(ctx context.Context, uID, imgID string) (*models.UserImage, error)
+		DeleteImage(ctx context.Context, uID, imgID string) error
+		DeleteAllImages(ctx context.Context, uID string) error
+		SetImageTags(ctx context.Context, uID, imgID string, tags []string) error
+		AddImageToAlbum(ctx context.Context, uID, imgID, albumID string) error
+		RemoveImageFromAlbum(ctx context.Context, uID, imgID, albumID string) error
+		IterateImages(ctx context.Context, uID string, from, to *time.Time, fn func(ui *models.UserImage) error) error
+		AlbumHandler
+		UsageHandler
+		ShareHandler
 	}
 
 	DynamoDBRepo struct {
-		TableName string
-		Client    *dynamodb.Client
-		Log       *logger.Logger
+		TableName      string
+		AlbumTableName string
+		ShareTableName string
+		Client         *dynamodb.Client
+		Log            *logger.Logger
+		httpClient     *http.Client
 	}
 )
 
@@ -40,36 +54,85 @@ const (
 	RangeKey             = "ImageID"
 	IndexRangeKey        = "TakenAt"
 	GlobalSecondaryIndex = "UserIDTakenAtIndex"
+
+	// maxUTCOffset is the largest offset a TakenAt can be sent with
+	maxUTCOffset = 14 * time.Hour
+	// takenAtSecondLayout is the start of TakenAt up to its second, before fraction and offset
+	takenAtSecondLayout = "2006-01-02T15:04:05"
+
+	// ImageDeleteConcurrency bounds the images marked deleted at once by DeleteAllImages
+	ImageDeleteConcurrency = 8
+
+	// tracerName names the spans started within the repo, they go to the global provider set up by tracing
+	tracerName = "github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	// attrImageID is the span attribute holding the image a span is about
+	attrImageID = "service_user.image_id"
 )
 
 func New(cfg *awsconfig.AWSConfig, env *config.Env, log *logger.Logger) *DynamoDBRepo {
-	return &DynamoDBRepo{TableName: env.DynamoDBTable, Client: createClient(cfg.Config), Log: log}
+	httpClient := awsconfig.HTTPClient(env.DynamoDBTimeout)
+	return &DynamoDBRepo{
+		TableName:      env.DynamoDBTable,
+		AlbumTableName: env.DynamoDBAlbumTable,
+		ShareTableName: env.DynamoDBShareTable,
+		Client:         createClient(cfg.Config, env.DynamoDBEndpoint, httpClient),
+		Log:            log,
+		httpClient:     httpClient,
+	}
 }
 
-func createClient(cfg *aws.Config) *dynamodb.Client {
-	return dynamodb.NewFromConfig(*cfg)
+func createClient(cfg *aws.Config, endpoint string, httpClient *http.Client) *dynamodb.Client {
+	return dynamodb.NewFromConfig(*cfg, func(o *dynamodb.Options) {
+		o.HTTPClient = httpClient
+		o.BaseEndpoint = awsconfig.Endpoint(endpoint)
+	})
+}
+
+// Close drops the idle connections to dynamoDB
+func (d *DynamoDBRepo) Close() error {
+	if d.httpClient != nil {
+		d.httpClient.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Ping describes the image, album and share tables, failing unless all of them are active
+func (d *DynamoDBRepo) Ping(ctx context.Context) error {
+	for _, table := range []string{d.TableName, d.AlbumTableName, d.ShareTableName} {
+		if table == "" {
+			continue
+		}
+		out, err := d.Client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return fmt.Errorf("describe table %s :: %w", table, err)
+		}
+		if status := out.Table.TableStatus; status != types.TableStatusActive {
+			return fmt.Errorf("table %s is %s", table, status)
+		}
+	}
+	return nil
 }
 
-func (d *DynamoDBRepo) AddImage(req *models.UserImage) error {
+func (d *DynamoDBRepo) AddImage(ctx context.Context, req *models.UserImage) error {
 	item, err := attributevalue.MarshalMap(req)
 	if err != nil {
-		d.Log.Error("error marshaling input", err)
+		d.Log.For(ctx).Error("error marshaling input", err)
 		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error marshaling input"))
 	}
 
-	_, err = d.Client.PutItem(context.Background(), &dynamodb.PutItemInput{
+	_, err = d.Client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: &d.TableName,
 		Item:      item,
 	})
 	if err != nil {
-		d.Log.Errorf("error persisting image %s of user %s to db %v", req.ImageID, req.UserID, err)
+		d.Log.For(ctx).Errorf("error persisting image %s of user %s to db %v", req.ImageID, req.UserID, err)
 		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error persisting image data"))
 	}
 
 	return nil
 }
 
-func (d *DynamoDBRepo) GetImage(uID, imgID string) (*models.UserImage, error) {
+func (d *DynamoDBRepo) GetImage(ctx context.Context, uID, imgID string) (*models.UserImage, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: &d.TableName,
 		Key: map[string]types.AttributeValue{
@@ -78,9 +141,9 @@ func (d *DynamoDBRepo) GetImage(uID, imgID string) (*models.UserImage, error) {
 		},
 	}
 
-	result, err := d.Client.GetItem(context.Background(), input)
+	result, err := d.Client.GetItem(ctx, input)
 	if err != nil {
-		d.Log.Error("error querying db", err)
+		d.Log.For(ctx).Error("error querying db", err)
 		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
 	}
 	if result.Item == nil {
@@ -94,115 +157,217 @@ func (d *DynamoDBRepo) GetImage(uID, imgID string) (*models.UserImage, error) {
 	var imageResult models.UserImage
 	err = attributevalue.UnmarshalMap(result.Item, &imageResult)
 	if err != nil {
-		d.Log.Error("error unmarshaling db response", err)
+		d.Log.For(ctx).Error("error unmarshaling db response", err)
 		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
 	}
 
 	return &imageResult, nil
 }
 
-func (d *DynamoDBRepo) DeleteImage(uID, imageID string) error {
-	imageResult, err := d.GetImage(uID, imageID)
+func (d *DynamoDBRepo) DeleteImage(ctx context.Context, uID, imageID string) error {
+	imageResult, err := d.GetImage(ctx, uID, imageID)
 	if err != nil {
 		return err
 	}
 
-	err = d.softDeleteItem(imageResult)
+	err = d.softDeleteItem(ctx, imageResult)
 	if err != nil {
-		d.Log.Errorf("error deleting image %s of user %s in DB. error :: %v", imageID, uID, err)
+		d.Log.For(ctx).Errorf("error deleting image %s of user %s in DB. error :: %v", imageID, uID, err)
 		return err
 	}
 
 	return nil
 }
 
-func (d *DynamoDBRepo) GetAllImagesPaginated(req models.PaginatedInput) (*models.UserImageResult, error) {
-	input := &dynamodb.QueryInput{
-		TableName:              &d.TableName,
-		IndexName:              aws.String(GlobalSecondaryIndex),
-		KeyConditionExpression: aws.String("UserID = :uID"),
-		FilterExpression:       aws.String("IsDeleted = :isDeleted"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":uID":       &types.AttributeValueMemberS{Value: req.UserID},
-			":isDeleted": &types.AttributeValueMemberBOOL{Value: false},
-		},
-		ScanIndexForward: aws.Bool(false),
-		Limit:            aws.Int32(req.Limit),
-	}
-
+// GetAllImagesPaginated returns up to req.Limit live images of the user, newest first.
+// Limit is applied by dynamoDB before the IsDeleted filter, so the query is repeated
+// until the page is full or the partition is exhausted.
+func (d *DynamoDBRepo) GetAllImagesPaginated(ctx context.Context, req models.PaginatedInput) (*models.UserImageResult, error) {
+	var startKey map[string]types.AttributeValue
 	if req.LastImageID != "" && req.LastImageTakenAt != "" {
-		input.ExclusiveStartKey = map[string]types.AttributeValue{
+		startKey = map[string]types.AttributeValue{
 			HashKey:       &types.AttributeValueMemberS{Value: req.UserID},
 			RangeKey:      &types.AttributeValueMemberS{Value: req.LastImageID},
 			IndexRangeKey: &types.AttributeValueMemberS{Value: req.LastImageTakenAt},
 		}
 	}
 
-	result, err := d.Client.Query(context.Background(), input)
-	if err != nil {
-		d.Log.Error("error querying db", err)
-		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+	response := &models.UserImageResult{
+		UserImages: make([]models.UserImage, 0, req.Limit),
 	}
 
-	var imageResults []models.UserImage
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &imageResults)
-	if err != nil {
-		d.Log.Error("error unmarshaling db response", err)
-		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+	filter, values := imageFilter(req)
+
+	for int32(len(response.UserImages)) < req.Limit {
+		input := &dynamodb.QueryInput{
+			TableName:                 &d.TableName,
+			IndexName:                 aws.String(GlobalSecondaryIndex),
+			KeyConditionExpression:    aws.String("UserID = :uID"),
+			FilterExpression:          aws.String(filter),
+			ExpressionAttributeValues: values,
+			ScanIndexForward:          aws.Bool(false),
+			Limit:                     aws.Int32(req.Limit - int32(len(response.UserImages))),
+			ExclusiveStartKey:         startKey,
+		}
+
+		result, err := d.Client.Query(ctx, input)
+		if err != nil {
+			d.Log.For(ctx).Error("error querying db", err)
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+		}
+
+		var imageResults []models.UserImage
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &imageResults)
+		if err != nil {
+			d.Log.For(ctx).Error("error unmarshaling db response", err)
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+		}
+		response.UserImages = append(response.UserImages, imageResults...)
+
+		startKey = result.LastEvaluatedKey
+		if startKey == nil {
+			break
+		}
 	}
-	response := &models.UserImageResult{
-		UserImages: imageResults,
-	}
-
-	if result.LastEvaluatedKey != nil {
-		newMap := make(map[string]string)
-		for k, v := range result.LastEvaluatedKey {
-			if attrS, ok := v.(*types.AttributeValueMemberS); ok {
-				switch k {
-				case RangeKey:
-					newMap[config.QueryParamLastKey] = attrS.Value
-				case IndexRangeKey:
-					newMap[config.QueryParamlastKeyDate] = attrS.Value
-				}
+
+	response.Page = toPage(startKey)
+
+	return response, nil
+}
+
+// imageFilter builds the filter expression of a paginated query, narrowed down to an album or tag if requested
+func imageFilter(req models.PaginatedInput) (string, map[string]types.AttributeValue) {
+	filter := "IsDeleted = :isDeleted"
+	values := map[string]types.AttributeValue{
+		":uID":       &types.AttributeValueMemberS{Value: req.UserID},
+		":isDeleted": &types.AttributeValueMemberBOOL{Value: false},
+	}
+
+	if req.AlbumID != "" {
+		filter += " AND contains(AlbumIDs, :albumID)"
+		values[":albumID"] = &types.AttributeValueMemberS{Value: req.AlbumID}
+	}
+	if req.Tag != "" {
+		filter += " AND contains(Tags, :tag)"
+		values[":tag"] = &types.AttributeValueMemberS{Value: req.Tag}
+	}
+
+	return filter, values
+}
+
+// takenAtKeyCondition narrows the query on the index to the images taken between from and to.
+// TakenAt is kept with the offset it was sent with and the index orders it as a string, so the bounds are widened
+// by the largest offset to a second of local time, the images are checked exactly once read.
+func takenAtKeyCondition(from, to *time.Time, values map[string]types.AttributeValue) string {
+	switch {
+	case from != nil && to != nil:
+		values[":from"], values[":to"] = takenAtLowerBound(*from), takenAtUpperBound(*to)
+		return "UserID = :uID AND TakenAt BETWEEN :from AND :to"
+	case from != nil:
+		values[":from"] = takenAtLowerBound(*from)
+		return "UserID = :uID AND TakenAt >= :from"
+	case to != nil:
+		values[":to"] = takenAtUpperBound(*to)
+		return "UserID = :uID AND TakenAt <= :to"
+	default:
+		return "UserID = :uID"
+	}
+}
+
+func takenAtLowerBound(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: t.UTC().Add(-maxUTCOffset).Format(takenAtSecondLayout)}
+}
+
+// takenAtUpperBound sorts after every fraction and offset following the second
+func takenAtUpperBound(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: t.UTC().Add(maxUTCOffset).Format(takenAtSecondLayout) + "~"}
+}
+
+// toPage converts the last evaluated key of a query into the cursor returned to clients
+func toPage(lastEvaluatedKey map[string]types.AttributeValue) models.Page {
+	page := models.Page{}
+	if lastEvaluatedKey == nil {
+		return page
+	}
+
+	page.LastEvaluatedKey = make(map[string]string)
+	for k, v := range lastEvaluatedKey {
+		if attrS, ok := v.(*types.AttributeValueMemberS); ok {
+			switch k {
+			case RangeKey:
+				page.LastEvaluatedKey[config.QueryParamLastKey] = attrS.Value
+			case IndexRangeKey:
+				page.LastEvaluatedKey[config.QueryParamlastKeyDate] = attrS.Value
 			}
-			response.Page.LastEvaluatedKey = newMap
 		}
 	}
 
-	return response, nil
+	return page
 }
 
-func (d *DynamoDBRepo) getAllItems(uID string) ([]models.UserImage, error) {
-	var lastEvaluatedKey map[string]types.AttributeValue
+// queryAllItems reads every live image of the user matching the filters of req, ignoring its limit and cursor
+func (d *DynamoDBRepo) queryAllItems(ctx context.Context, req models.PaginatedInput) ([]models.UserImage, error) {
 	var allImages []models.UserImage
 
+	err := d.forEachItem(ctx, req, nil, nil, false, func(ui *models.UserImage) error {
+		allImages = append(allImages, *ui)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allImages, nil
+}
+
+// IterateImages calls fn for every live image of the user taken between the optional from and to, oldest first.
+// Only one page of the query is held in memory at a time.
+func (d *DynamoDBRepo) IterateImages(ctx context.Context, uID string, from, to *time.Time, fn func(ui *models.UserImage) error) error {
+	return d.forEachItem(ctx, models.PaginatedInput{UserID: uID}, from, to, true, func(ui *models.UserImage) error {
+		if !models.TakenBetween(ui.TakenAt, from, to) {
+			return nil
+		}
+		return fn(ui)
+	})
+}
+
+// forEachItem pages through the live images of the user matching the filters of req and calls fn for each,
+// stopping at the first error. The optional from and to narrow the query on the index by TakenAt.
+func (d *DynamoDBRepo) forEachItem(ctx context.Context, req models.PaginatedInput, from, to *time.Time, oldestFirst bool,
+	fn func(ui *models.UserImage) error) error {
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	filter, values := imageFilter(req)
+	keyCondition := takenAtKeyCondition(from, to, values)
+
 	for {
 		var imageResults []models.UserImage
 		input := &dynamodb.QueryInput{
-			TableName:              &d.TableName,
-			IndexName:              aws.String("UserIDTakenAtIndex"),
-			KeyConditionExpression: aws.String("UserID = :uID"),
-			FilterExpression:       aws.String("IsDeleted = :isDeleted"),
-			ExpressionAttributeValues: map[string]types.AttributeValue{
-				":uID":       &types.AttributeValueMemberS{Value: uID},
-				":isDeleted": &types.AttributeValueMemberBOOL{Value: false},
-			},
-			ScanIndexForward:  aws.Bool(false),
-			ExclusiveStartKey: lastEvaluatedKey,
+			TableName:                 &d.TableName,
+			IndexName:                 aws.String(GlobalSecondaryIndex),
+			KeyConditionExpression:    aws.String(keyCondition),
+			FilterExpression:          aws.String(filter),
+			ExpressionAttributeValues: values,
+			ScanIndexForward:          aws.Bool(oldestFirst),
+			ExclusiveStartKey:         lastEvaluatedKey,
 		}
 
-		result, err := d.Client.Query(context.Background(), input)
+		result, err := d.Client.Query(ctx, input)
 		if err != nil {
-			d.Log.Error("error querying db", err)
-			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+			d.Log.For(ctx).Error("error querying db", err)
+			return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
 		}
 
 		err = attributevalue.UnmarshalListOfMaps(result.Items, &imageResults)
 		if err != nil {
-			d.Log.Error("error unmarshaling db response", err)
-			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+			d.Log.For(ctx).Error("error unmarshaling db response", err)
+			return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+		}
+		for i := range imageResults {
+			if err := fn(&imageResults[i]); err != nil {
+				return err
+			}
 		}
-		allImages = append(allImages, imageResults...)
 
 		if result.LastEvaluatedKey == nil {
 			break
@@ -211,59 +376,133 @@ func (d *DynamoDBRepo) getAllItems(uID string) ([]models.UserImage, error) {
 		lastEvaluatedKey = result.LastEvaluatedKey
 	}
 
-	return allImages, nil
+	return nil
 }
 
-func (d *DynamoDBRepo) softDeleteItem(req *models.UserImage) error {
+// softDeleteItem marks the image deleted and gives its size back to the user's usage in one transaction.
+// An image that is already deleted is left untouched, so its usage is never released twice.
+func (d *DynamoDBRepo) softDeleteItem(ctx context.Context, req *models.UserImage) error {
+	items := []types.TransactWriteItem{{Update: d.softDeleteUpdate(req)}}
+	// images stored before usage tracking have no size and were never counted
+	if req.Size > 0 {
+		items = append(items, types.TransactWriteItem{Update: d.usageUpdate(req.UserID, -req.Size, -1)})
+	}
 
-	_, err := d.Client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+	_, err := d.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if err != nil {
+		if isTransactionConditionFailed(err) {
+			d.Log.For(ctx).Debugf("image %s of user %s is already deleted", req.ImageID, req.UserID)
+			return nil
+		}
+		d.Log.For(ctx).Errorf("error persisting scan %s of user %s. error %v", req.ImageID, req.UserID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing image"))
+	}
+
+	return nil
+}
+
+// markDeleted marks the image deleted, leaving its usage to the caller.
+// It returns false for an image that is already deleted.
+func (d *DynamoDBRepo) markDeleted(ctx context.Context, req *models.UserImage) (bool, error) {
+	update := d.softDeleteUpdate(req)
+	_, err := d.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 update.TableName,
+		Key:                       update.Key,
+		ConditionExpression:       update.ConditionExpression,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		UpdateExpression:          update.UpdateExpression,
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			d.Log.For(ctx).Debugf("image %s of user %s is already deleted", req.ImageID, req.UserID)
+			return false, nil
+		}
+		d.Log.For(ctx).Errorf("error persisting scan %s of user %s. error %v", req.ImageID, req.UserID, err)
+		return false, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing image"))
+	}
+
+	return true, nil
+}
+
+// markDeletedTraced marks the image deleted in a span of its own, a child of the span of the whole call
+func (d *DynamoDBRepo) markDeletedTraced(ctx context.Context, req *models.UserImage) (bool, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, config.ImageDBDynamo+".DeleteAllImages.image",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String(attrImageID, req.ImageID)),
+	)
+	defer span.End()
+
+	deleted, err := d.markDeleted(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return deleted, err
+}
+
+// softDeleteUpdate marks an image deleted unless it already is
+func (d *DynamoDBRepo) softDeleteUpdate(req *models.UserImage) *types.Update {
+	return &types.Update{
 		TableName: &d.TableName,
 		Key: map[string]types.AttributeValue{
 			HashKey:  &types.AttributeValueMemberS{Value: req.UserID},
 			RangeKey: &types.AttributeValueMemberS{Value: req.ImageID},
 		},
+		ConditionExpression: aws.String("IsDeleted = :notDeleted"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":isDeleted": &types.AttributeValueMemberBOOL{Value: true},
-			":updatedAt": &types.AttributeValueMemberS{Value: time.Now().String()},
+			":isDeleted":  &types.AttributeValueMemberBOOL{Value: true},
+			":notDeleted": &types.AttributeValueMemberBOOL{Value: false},
+			":updatedAt":  &types.AttributeValueMemberS{Value: time.Now().String()},
 		},
-		UpdateExpression: aws.String("SET IsDeleted = :isDeleted, UpdatedAt = :updatedAt"),
-	})
-	if err != nil {
-		d.Log.Errorf("error persisting scan %s of user %s. error %v", req.ImageID, req.UserID, err)
-		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing image"))
+		// a deleted image no longer belongs to any album
+		UpdateExpression: aws.String("SET IsDeleted = :isDeleted, UpdatedAt = :updatedAt REMOVE AlbumIDs"),
 	}
-
-	return nil
 }
 
-func (d *DynamoDBRepo) DeleteAllImages(uID string) error {
-	imageResults, err := d.getAllItems(uID)
+// DeleteAllImages marks the images of the user deleted, at most ImageDeleteConcurrency at a time, and gives
+// their usage back in one update at the end. Transactions per image would all write the same usage item and
+// cancel each other.
+func (d *DynamoDBRepo) DeleteAllImages(ctx context.Context, uID string) error {
+	imageResults, err := d.queryAllItems(ctx, models.PaginatedInput{UserID: uID})
 	if err != nil {
 		return err
 	}
 
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(imageResults))
-
-	for _, item := range imageResults {
-		wg.Add(1)
-		go func(item models.UserImage) {
-			defer wg.Done()
-			err := d.softDeleteItem(&item)
-			if err != nil {
-				errChan <- err
+	var (
+		mu          sync.Mutex
+		size, count int64
+		g           errgroup.Group
+	)
+	g.SetLimit(ImageDeleteConcurrency)
+	for i := range imageResults {
+		item := &imageResults[i]
+		g.Go(func() error {
+			deleted, err := d.markDeletedTraced(ctx, item)
+			// images stored before usage tracking have no size and were never counted
+			if err != nil || !deleted || item.Size <= 0 {
+				return err
 			}
-		}(item)
+			mu.Lock()
+			size += item.Size
+			count++
+			mu.Unlock()
+			return nil
+		})
 	}
-	wg.Wait()
-	close(errChan)
+	err = g.Wait()
 
-	for err := range errChan {
-		if err != nil {
-			d.Log.Errorf("error deleting images of user %s in DB. error :: %v", uID, err)
-			return err
+	// the images marked deleted give their usage back even if others failed or the request is gone
+	if count > 0 {
+		if uerr := d.addUsage(context.WithoutCancel(ctx), uID, -size, -count); uerr != nil && err == nil {
+			err = uerr
 		}
 	}
+	if err != nil {
+		d.Log.For(ctx).Errorf("error deleting images of user %s in DB. error :: %v", uID, err)
+		return err
+	}
 
 	return nil
 }
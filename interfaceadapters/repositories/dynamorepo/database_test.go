@@ -1,6 +1,7 @@
 package dynamorepo
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -24,9 +25,11 @@ func (s *RepoTestSuite) SetupSuite() {
 
 func (s *RepoTestSuite) SetupTest() {
 	s.repo = &DynamoDBRepo{
-		TableName: integrationtest.UserImageTable,
-		Client:    s.dynamoSetup.Client,
-		Log:       logger.New(),
+		TableName:      integrationtest.UserImageTable,
+		AlbumTableName: integrationtest.UserAlbumTable,
+		ShareTableName: integrationtest.UserImageShareTable,
+		Client:         s.dynamoSetup.Client,
+		Log:            logger.New(),
 	}
 }
 
@@ -48,9 +51,9 @@ func (s *RepoTestSuite) TestShouldSaveImage() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err := s.repo.AddImage(createReq)
+	err := s.repo.AddImage(context.Background(), createReq)
 
-	result, _ := s.repo.GetImage("123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	result, _ := s.repo.GetImage(context.Background(), "123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), "128a68e4-a10a-11ef-ba63-c689f470ad55", result.ImageID)
 }
@@ -64,7 +67,7 @@ func (s *RepoTestSuite) TestShouldNotSaveImageWithoutUserID() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err := s.repo.AddImage(createReqWithoutUserID)
+	err := s.repo.AddImage(context.Background(), createReqWithoutUserID)
 
 	assert.NotNil(s.T(), err)
 }
@@ -78,7 +81,7 @@ func (s *RepoTestSuite) TestShouldNotSaveImageWithoutImageID() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err := s.repo.AddImage(createReqWithoutImageID)
+	err := s.repo.AddImage(context.Background(), createReqWithoutImageID)
 
 	assert.NotNil(s.T(), err)
 }
@@ -93,16 +96,16 @@ func (s *RepoTestSuite) TestShouldGetImage() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err := s.repo.AddImage(createReq)
+	err := s.repo.AddImage(context.Background(), createReq)
 
-	result, _ := s.repo.GetImage("123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	result, _ := s.repo.GetImage(context.Background(), "123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), "128a68e4-a10a-11ef-ba63-c689f470ad55", result.ImageID)
 }
 
 func (s *RepoTestSuite) TestShouldNotGetImageIfNotExist() {
 
-	_, err := s.repo.GetImage("345", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	_, err := s.repo.GetImage(context.Background(), "345", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), "image not found", err.Error())
 }
@@ -120,7 +123,7 @@ func (s *RepoTestSuite) TestShouldGetAllImagePaginated() {
 		TakenAt:   todayTime,
 		UpdatedAt: todayTime,
 	}
-	err := s.repo.AddImage(createReq1)
+	err := s.repo.AddImage(context.Background(), createReq1)
 
 	createReq2 := &models.UserImage{
 		IsDeleted: false,
@@ -130,7 +133,7 @@ func (s *RepoTestSuite) TestShouldGetAllImagePaginated() {
 		TakenAt:   yesterdayTime,
 		UpdatedAt: yesterdayTime,
 	}
-	err = s.repo.AddImage(createReq2)
+	err = s.repo.AddImage(context.Background(), createReq2)
 
 	getReq1 := models.PaginatedInput{
 		UserID:           "999",
@@ -139,7 +142,7 @@ func (s *RepoTestSuite) TestShouldGetAllImagePaginated() {
 		Limit:            1,
 	}
 
-	data, err := s.repo.GetAllImagesPaginated(getReq1)
+	data, err := s.repo.GetAllImagesPaginated(context.Background(), getReq1)
 
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), 1, len(data.UserImages))
@@ -153,7 +156,7 @@ func (s *RepoTestSuite) TestShouldGetAllImagePaginated() {
 		Limit:            1,
 	}
 
-	data, err = s.repo.GetAllImagesPaginated(getReq2)
+	data, err = s.repo.GetAllImagesPaginated(context.Background(), getReq2)
 
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), 1, len(data.UserImages))
@@ -167,7 +170,7 @@ func (s *RepoTestSuite) TestShouldGetAllImagePaginated() {
 		Limit:            1,
 	}
 
-	data, err = s.repo.GetAllImagesPaginated(getReq3)
+	data, err = s.repo.GetAllImagesPaginated(context.Background(), getReq3)
 
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), 0, len(data.UserImages))
@@ -183,7 +186,7 @@ func (s *RepoTestSuite) TestShouldNotGetAllImagePaginatedIfNotExist() {
 		Limit:            1,
 	}
 
-	data, err := s.repo.GetAllImagesPaginated(req)
+	data, err := s.repo.GetAllImagesPaginated(context.Background(), req)
 
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), 0, len(data.UserImages))
@@ -200,23 +203,23 @@ func (s *RepoTestSuite) TestShouldDeleteImage() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err := s.repo.AddImage(createReq)
+	err := s.repo.AddImage(context.Background(), createReq)
 
-	err = s.repo.DeleteImage("123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	err = s.repo.DeleteImage(context.Background(), "123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.Nil(s.T(), err)
 
-	_, err = s.repo.GetImage("123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	_, err = s.repo.GetImage(context.Background(), "123", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), "image not found", err.Error())
 }
 
 func (s *RepoTestSuite) TestShouldNotDeleteImageIfNotExist() {
 
-	err := s.repo.DeleteImage("345", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	err := s.repo.DeleteImage(context.Background(), "345", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), "image not found", err.Error())
 
-	_, err = s.repo.GetImage("345", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	_, err = s.repo.GetImage(context.Background(), "345", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), "image not found", err.Error())
 }
@@ -231,7 +234,7 @@ func (s *RepoTestSuite) TestShouldDeleteAllImage() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err := s.repo.AddImage(createReq1)
+	err := s.repo.AddImage(context.Background(), createReq1)
 
 	createReq2 := &models.UserImage{
 		IsDeleted: false,
@@ -241,7 +244,7 @@ func (s *RepoTestSuite) TestShouldDeleteAllImage() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err = s.repo.AddImage(createReq2)
+	err = s.repo.AddImage(context.Background(), createReq2)
 
 	createReq3 := &models.UserImage{
 		IsDeleted: false,
@@ -251,16 +254,65 @@ func (s *RepoTestSuite) TestShouldDeleteAllImage() {
 		TakenAt:   time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	err = s.repo.AddImage(createReq3)
+	err = s.repo.AddImage(context.Background(), createReq3)
 
-	err = s.repo.DeleteAllImages("897")
+	err = s.repo.DeleteAllImages(context.Background(), "897")
 	assert.Nil(s.T(), err)
 
-	_, err = s.repo.GetImage("897", "128a68e4-a10a-11ef-ba63-c689f470ad55")
+	_, err = s.repo.GetImage(context.Background(), "897", "128a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), "image not found", err.Error())
 
-	_, err = s.repo.GetImage("897", "228a68e4-a10a-11ef-ba63-c689f470ad55")
+	_, err = s.repo.GetImage(context.Background(), "897", "228a68e4-a10a-11ef-ba63-c689f470ad55")
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), "image not found", err.Error())
 }
+
+func (s *RepoTestSuite) TestShouldGetFullPageWhenImagesAreSoftDeleted() {
+
+	todayTime := time.Now()
+
+	for i, imageID := range []string{"aaaaaaa-1111111", "bbbbbbb-2222222", "ccccccc-3333333", "ddddddd-4444444"} {
+		createReq := &models.UserImage{
+			IsDeleted: false,
+			UserID:    "777",
+			ImageID:   imageID,
+			Path:      "story-image/777/" + imageID + ".jpg",
+			TakenAt:   todayTime.AddDate(0, 0, -i),
+			UpdatedAt: todayTime,
+		}
+		err := s.repo.AddImage(context.Background(), createReq)
+		assert.Nil(s.T(), err)
+	}
+
+	// the two newest images are filtered out by the IsDeleted filter
+	err := s.repo.DeleteImage(context.Background(), "777", "aaaaaaa-1111111")
+	assert.Nil(s.T(), err)
+	err = s.repo.DeleteImage(context.Background(), "777", "bbbbbbb-2222222")
+	assert.Nil(s.T(), err)
+
+	getReq1 := models.PaginatedInput{
+		UserID: "777",
+		Limit:  2,
+	}
+
+	data, err := s.repo.GetAllImagesPaginated(context.Background(), getReq1)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, len(data.UserImages))
+	assert.Equal(s.T(), "ccccccc-3333333", data.UserImages[0].ImageID)
+	assert.Equal(s.T(), "ddddddd-4444444", data.UserImages[1].ImageID)
+
+	getReq2 := models.PaginatedInput{
+		UserID:           "777",
+		LastImageID:      data.Page.LastEvaluatedKey[config.QueryParamLastKey],
+		LastImageTakenAt: data.Page.LastEvaluatedKey[config.QueryParamlastKeyDate],
+		Limit:            2,
+	}
+
+	data, err = s.repo.GetAllImagesPaginated(context.Background(), getReq2)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 0, len(data.UserImages))
+	assert.Equal(s.T(), 0, len(data.Page.LastEvaluatedKey))
+}
@@ -0,0 +1,286 @@
+// Package dynamorepotest holds the contract every dynamorepo.DataHandler implementation has to fulfill
+package dynamorepotest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// ContractSuite runs against the repo returned by NewRepo. Implementations may share state
+// between tests, so every test works on its own user.
+type ContractSuite struct {
+	suite.Suite
+	NewRepo func() dynamorepo.DataHandler
+	repo    dynamorepo.DataHandler
+}
+
+func (s *ContractSuite) SetupTest() {
+	s.repo = s.NewRepo()
+}
+
+// addImages stores an image per id, each taken a day before the previous one
+func (s *ContractSuite) addImages(uID string, size int64, imageIDs ...string) {
+	takenAt := time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)
+	for i, imageID := range imageIDs {
+		err := s.repo.AddImage(context.Background(), &models.UserImage{
+			UserID:    uID,
+			ImageID:   imageID,
+			Path:      "story-images/" + uID + "/" + imageID + ".jpg",
+			TakenAt:   takenAt.AddDate(0, 0, -i),
+			UpdatedAt: takenAt,
+			Size:      size,
+		})
+		assert.Nil(s.T(), err)
+	}
+}
+
+func (s *ContractSuite) page(uID string, limit int32, cursor models.Page) *models.UserImageResult {
+	data, err := s.repo.GetAllImagesPaginated(context.Background(), models.PaginatedInput{
+		UserID:           uID,
+		LastImageID:      cursor.LastEvaluatedKey[config.QueryParamLastKey],
+		LastImageTakenAt: cursor.LastEvaluatedKey[config.QueryParamlastKeyDate],
+		Limit:            limit,
+	})
+	assert.Nil(s.T(), err)
+	return data
+}
+
+func imageIDs(images []models.UserImage) []string {
+	ids := make([]string, 0, len(images))
+	for i := range images {
+		ids = append(ids, images[i].ImageID)
+	}
+	return ids
+}
+
+func newUserID() string {
+	return uuid.NewString()
+}
+
+func assertErrCode(s *ContractSuite, code string, err error) {
+	apiErr, ok := err.(errors.Error)
+	if assert.True(s.T(), ok, "expected a domain error, got %v", err) {
+		assert.Equal(s.T(), code, apiErr.Code)
+	}
+}
+
+func (s *ContractSuite) TestImageRoundTrip() {
+	uID := newUserID()
+	s.addImages(uID, 10, "img-1")
+
+	img, err := s.repo.GetImage(context.Background(), uID, "img-1")
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "img-1", img.ImageID)
+	assert.Equal(s.T(), int64(10), img.Size)
+	assert.True(s.T(), img.TakenAt.Equal(time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)))
+}
+
+func (s *ContractSuite) TestAddImageRequiresKeys() {
+	assert.NotNil(s.T(), s.repo.AddImage(context.Background(), &models.UserImage{ImageID: "img-1", TakenAt: time.Now()}))
+	assert.NotNil(s.T(), s.repo.AddImage(context.Background(), &models.UserImage{UserID: newUserID(), TakenAt: time.Now()}))
+}
+
+func (s *ContractSuite) TestGetMissingImage() {
+	_, err := s.repo.GetImage(context.Background(), newUserID(), "img-1")
+
+	assertErrCode(s, errors.ErrCodeNotFound, err)
+}
+
+func (s *ContractSuite) TestPagesAreOrderedNewestFirst() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1", "img-2", "img-3")
+
+	first := s.page(uID, 2, models.Page{})
+	assert.Equal(s.T(), []string{"img-1", "img-2"}, imageIDs(first.UserImages))
+	assert.Equal(s.T(), "img-2", first.Page.LastEvaluatedKey[config.QueryParamLastKey])
+
+	second := s.page(uID, 2, first.Page)
+	assert.Equal(s.T(), []string{"img-3"}, imageIDs(second.UserImages))
+	assert.Empty(s.T(), second.Page.LastEvaluatedKey)
+}
+
+func (s *ContractSuite) TestFullLastPageStillHasCursor() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1", "img-2")
+
+	first := s.page(uID, 2, models.Page{})
+	assert.Equal(s.T(), []string{"img-1", "img-2"}, imageIDs(first.UserImages))
+	assert.NotEmpty(s.T(), first.Page.LastEvaluatedKey)
+
+	second := s.page(uID, 2, first.Page)
+	assert.Empty(s.T(), second.UserImages)
+	assert.Empty(s.T(), second.Page.LastEvaluatedKey)
+}
+
+func (s *ContractSuite) TestPagesSkipSoftDeletedImages() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1", "img-2", "img-3", "img-4")
+	assert.Nil(s.T(), s.repo.DeleteImage(context.Background(), uID, "img-1"))
+	assert.Nil(s.T(), s.repo.DeleteImage(context.Background(), uID, "img-3"))
+
+	first := s.page(uID, 2, models.Page{})
+
+	assert.Equal(s.T(), []string{"img-2", "img-4"}, imageIDs(first.UserImages))
+}
+
+func (s *ContractSuite) TestDeletedImageIsNotFound() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1")
+
+	assert.Nil(s.T(), s.repo.DeleteImage(context.Background(), uID, "img-1"))
+
+	_, err := s.repo.GetImage(context.Background(), uID, "img-1")
+	assertErrCode(s, errors.ErrCodeNotFound, err)
+	assertErrCode(s, errors.ErrCodeNotFound, s.repo.DeleteImage(context.Background(), uID, "img-1"))
+	assertErrCode(s, errors.ErrCodeNotFound, s.repo.SetImageTags(context.Background(), uID, "img-1", []string{"beach"}))
+}
+
+func (s *ContractSuite) TestDeleteAllImages() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1", "img-2", "img-3")
+
+	assert.Nil(s.T(), s.repo.DeleteAllImages(context.Background(), uID))
+
+	assert.Empty(s.T(), s.page(uID, 10, models.Page{}).UserImages)
+}
+
+func (s *ContractSuite) TestIterateImagesOldestFirst() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1", "img-2", "img-3")
+	assert.Nil(s.T(), s.repo.DeleteImage(context.Background(), uID, "img-2"))
+
+	var ids []string
+	err := s.repo.IterateImages(context.Background(), uID, nil, nil, func(ui *models.UserImage) error {
+		ids = append(ids, ui.ImageID)
+		return nil
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"img-3", "img-1"}, ids)
+}
+
+func (s *ContractSuite) TestIterateImagesInRange() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1", "img-2", "img-3", "img-4")
+	// an image sent with an offset, 2024-11-18T21:00:00Z, in range by its instant but not by its local time
+	err := s.repo.AddImage(context.Background(), &models.UserImage{
+		UserID:  uID,
+		ImageID: "img-5",
+		Path:    "story-images/" + uID + "/img-5.jpg",
+		TakenAt: time.Date(2024, 11, 19, 9, 0, 0, 0, time.FixedZone("", 12*60*60)),
+	})
+	assert.Nil(s.T(), err)
+	from := time.Date(2024, 11, 18, 10, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 11, 19, 8, 0, 0, 0, time.UTC)
+
+	var ids []string
+	err = s.repo.IterateImages(context.Background(), uID, &from, &to, func(ui *models.UserImage) error {
+		ids = append(ids, ui.ImageID)
+		return nil
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"img-3", "img-5"}, ids)
+}
+
+func (s *ContractSuite) TestFilterByTagAndAlbum() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1", "img-2", "img-3")
+	assert.Nil(s.T(), s.repo.SetImageTags(context.Background(), uID, "img-1", []string{"beach", "sun"}))
+	assert.Nil(s.T(), s.repo.SetImageTags(context.Background(), uID, "img-3", []string{"beach"}))
+	assert.Nil(s.T(), s.repo.CreateAlbum(context.Background(), &models.Album{UserID: uID, AlbumID: "album-1", Name: "holiday"}))
+	assert.Nil(s.T(), s.repo.AddImageToAlbum(context.Background(), uID, "img-2", "album-1"))
+
+	byTag, err := s.repo.GetAllImagesPaginated(context.Background(), models.PaginatedInput{UserID: uID, Tag: "beach", Limit: 10})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"img-1", "img-3"}, imageIDs(byTag.UserImages))
+
+	byAlbum, err := s.repo.GetAllImagesPaginated(context.Background(), models.PaginatedInput{UserID: uID, AlbumID: "album-1", Limit: 10})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"img-2"}, imageIDs(byAlbum.UserImages))
+
+	assert.Nil(s.T(), s.repo.SetImageTags(context.Background(), uID, "img-1", nil))
+	img, _ := s.repo.GetImage(context.Background(), uID, "img-1")
+	assert.Empty(s.T(), img.Tags)
+}
+
+func (s *ContractSuite) TestDeleteAlbumKeepsImages() {
+	uID := newUserID()
+	s.addImages(uID, 0, "img-1")
+	assert.Nil(s.T(), s.repo.CreateAlbum(context.Background(), &models.Album{UserID: uID, AlbumID: "album-1", Name: "holiday"}))
+	assert.Nil(s.T(), s.repo.AddImageToAlbum(context.Background(), uID, "img-1", "album-1"))
+
+	renamed, err := s.repo.RenameAlbum(context.Background(), uID, "album-1", "summer")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "summer", renamed.Name)
+
+	assert.Nil(s.T(), s.repo.DeleteAlbum(context.Background(), uID, "album-1"))
+
+	_, err = s.repo.GetAlbum(context.Background(), uID, "album-1")
+	assertErrCode(s, errors.ErrCodeNotFound, err)
+	img, err := s.repo.GetImage(context.Background(), uID, "img-1")
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), img.AlbumIDs)
+}
+
+func (s *ContractSuite) TestUsageFollowsReservationsAndDeletes() {
+	uID := newUserID()
+	quota := models.Quota{MaxBytes: 100, MaxImages: 2}
+
+	assert.Nil(s.T(), s.repo.ReserveUsage(context.Background(), uID, 40, quota))
+	assert.Nil(s.T(), s.repo.ReserveUsage(context.Background(), uID, 40, quota))
+	assertErrCode(s, errors.ErrCodeQuotaExceeded, s.repo.ReserveUsage(context.Background(), uID, 10, quota))
+
+	s.addImages(uID, 40, "img-1")
+	assert.Nil(s.T(), s.repo.DeleteImage(context.Background(), uID, "img-1"))
+	assert.Nil(s.T(), s.repo.ReleaseUsage(context.Background(), uID, 40))
+
+	usage, err := s.repo.GetUsage(context.Background(), uID)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), models.Usage{}, *usage)
+}
+
+func (s *ContractSuite) TestDeleteAllImagesGivesUsageBack() {
+	uID := newUserID()
+	quota := models.Quota{MaxBytes: 1000, MaxImages: 100}
+	ids := make([]string, 0, 30)
+	for i := 0; i < 30; i++ {
+		assert.Nil(s.T(), s.repo.ReserveUsage(context.Background(), uID, 10, quota))
+		ids = append(ids, fmt.Sprintf("img-%02d", i))
+	}
+	s.addImages(uID, 10, ids...)
+
+	assert.Nil(s.T(), s.repo.DeleteAllImages(context.Background(), uID))
+	// deleted images are not given back twice
+	assert.Nil(s.T(), s.repo.DeleteAllImages(context.Background(), uID))
+
+	usage, err := s.repo.GetUsage(context.Background(), uID)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), models.Usage{}, *usage)
+}
+
+func (s *ContractSuite) TestSharesAreScopedToOwner() {
+	ownerID := newUserID()
+	share := &models.Share{ShareID: uuid.NewString(), OwnerID: ownerID, ImageID: "img-1", CreatedAt: time.Now()}
+	assert.Nil(s.T(), s.repo.CreateShare(context.Background(), share))
+
+	shares, err := s.repo.ListShares(context.Background(), ownerID, "img-1")
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), shares, 1)
+
+	assertErrCode(s, errors.ErrCodeNotFound, s.repo.DeleteShare(context.Background(), newUserID(), share.ShareID))
+	assert.Nil(s.T(), s.repo.DeleteShare(context.Background(), ownerID, share.ShareID))
+
+	_, err = s.repo.GetShare(context.Background(), share.ShareID)
+	assertErrCode(s, errors.ErrCodeNotFound, err)
+}
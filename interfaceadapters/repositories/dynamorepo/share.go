@@ -0,0 +1,139 @@
+package dynamorepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+)
+
+type (
+	ShareHandler interface {
+		CreateShare(ctx context.Context, sh *models.Share) error
+		GetShare(ctx context.Context, shareID string) (*models.Share, error)
+		ListShares(ctx context.Context, ownerID, imgID string) ([]models.Share, error)
+		DeleteShare(ctx context.Context, ownerID, shareID string) error
+	}
+)
+
+const (
+	ShareHashKey    = "ShareID"
+	ShareOwnerIndex = "OwnerIDImageIDIndex"
+)
+
+func (d *DynamoDBRepo) CreateShare(ctx context.Context, req *models.Share) error {
+	item, err := attributevalue.MarshalMap(req)
+	if err != nil {
+		d.Log.For(ctx).Error("error marshaling input", err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error marshaling input"))
+	}
+
+	_, err = d.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &d.ShareTableName,
+		Item:      item,
+	})
+	if err != nil {
+		d.Log.For(ctx).Errorf("error persisting share of image %s of user %s to db %v", req.ImageID, req.OwnerID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error persisting share data"))
+	}
+
+	return nil
+}
+
+func (d *DynamoDBRepo) GetShare(ctx context.Context, shareID string) (*models.Share, error) {
+	result, err := d.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &d.ShareTableName,
+		Key: map[string]types.AttributeValue{
+			ShareHashKey: &types.AttributeValueMemberS{Value: shareID},
+		},
+	})
+	if err != nil {
+		d.Log.For(ctx).Error("error querying db", err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+	}
+	if result.Item == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("share not found"))
+	}
+
+	var share models.Share
+	err = attributevalue.UnmarshalMap(result.Item, &share)
+	if err != nil {
+		d.Log.For(ctx).Error("error unmarshaling db response", err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+	}
+
+	return &share, nil
+}
+
+// ListShares returns the shares created by the owner, only those of one image if imgID is set
+func (d *DynamoDBRepo) ListShares(ctx context.Context, ownerID, imgID string) ([]models.Share, error) {
+	keyCondition := "OwnerID = :ownerID"
+	values := map[string]types.AttributeValue{
+		":ownerID": &types.AttributeValueMemberS{Value: ownerID},
+	}
+	if imgID != "" {
+		keyCondition += " AND ImageID = :imageID"
+		values[":imageID"] = &types.AttributeValueMemberS{Value: imgID}
+	}
+
+	var lastEvaluatedKey map[string]types.AttributeValue
+	shares := make([]models.Share, 0)
+
+	for {
+		result, err := d.Client.Query(ctx, &dynamodb.QueryInput{
+			TableName:                 &d.ShareTableName,
+			IndexName:                 aws.String(ShareOwnerIndex),
+			KeyConditionExpression:    aws.String(keyCondition),
+			ExpressionAttributeValues: values,
+			ExclusiveStartKey:         lastEvaluatedKey,
+		})
+		if err != nil {
+			d.Log.For(ctx).Error("error querying db", err)
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+		}
+
+		var shareResults []models.Share
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &shareResults)
+		if err != nil {
+			d.Log.For(ctx).Error("error unmarshaling db response", err)
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+		}
+		shares = append(shares, shareResults...)
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return shares, nil
+}
+
+// DeleteShare revokes a share, only if it was created by the owner
+func (d *DynamoDBRepo) DeleteShare(ctx context.Context, ownerID, shareID string) error {
+	_, err := d.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &d.ShareTableName,
+		Key: map[string]types.AttributeValue{
+			ShareHashKey: &types.AttributeValueMemberS{Value: shareID},
+		},
+		ConditionExpression: aws.String("OwnerID = :ownerID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ownerID": &types.AttributeValueMemberS{Value: ownerID},
+		},
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return errors.New(errors.ErrCodeNotFound, fmt.Errorf("share not found"))
+		}
+		d.Log.For(ctx).Errorf("error deleting share %s of user %s. error %v", shareID, ownerID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing share"))
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package dynamorepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *RepoTestSuite) TestShouldCreateListAndRevokeShares() {
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	err := s.repo.CreateShare(context.Background(), &models.Share{
+		ShareID:   "g-222-jjjjjjj-1111111-223",
+		OwnerID:   "222",
+		ImageID:   "jjjjjjj-1111111",
+		GranteeID: "223",
+		CreatedAt: time.Now(),
+	})
+	assert.Nil(s.T(), err)
+
+	err = s.repo.CreateShare(context.Background(), &models.Share{
+		ShareID:   "some-public-token",
+		OwnerID:   "222",
+		ImageID:   "kkkkkkk-2222222",
+		ExpiresAt: &expiresAt,
+		CreatedAt: time.Now(),
+		TTL:       expiresAt.Unix(),
+	})
+	assert.Nil(s.T(), err)
+
+	share, err := s.repo.GetShare(context.Background(), "some-public-token")
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), share.IsPublicLink())
+	assert.Equal(s.T(), "kkkkkkk-2222222", share.ImageID)
+
+	shares, err := s.repo.ListShares(context.Background(), "222", "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, len(shares))
+
+	shares, err = s.repo.ListShares(context.Background(), "222", "jjjjjjj-1111111")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, len(shares))
+	assert.Equal(s.T(), "223", shares[0].GranteeID)
+
+	// only the owner can revoke
+	err = s.repo.DeleteShare(context.Background(), "223", "some-public-token")
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), "share not found", err.Error())
+
+	err = s.repo.DeleteShare(context.Background(), "222", "some-public-token")
+	assert.Nil(s.T(), err)
+
+	_, err = s.repo.GetShare(context.Background(), "some-public-token")
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), "share not found", err.Error())
+}
@@ -0,0 +1,144 @@
+package dynamorepo
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+)
+
+type (
+	UsageHandler interface {
+		GetUsage(ctx context.Context, uID string) (*models.Usage, error)
+		ReserveUsage(ctx context.Context, uID string, size int64, quota models.Quota) error
+		ReleaseUsage(ctx context.Context, uID string, size int64) error
+	}
+)
+
+const (
+	// UsageRangeKey is the range key of the per user usage counter in the image table.
+	// The counter has no TakenAt, so it never shows up in queries on the TakenAt index.
+	UsageRangeKey = "#usage"
+)
+
+func (d *DynamoDBRepo) GetUsage(ctx context.Context, uID string) (*models.Usage, error) {
+	result, err := d.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &d.TableName,
+		Key:            usageKey(uID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		d.Log.For(ctx).Error("error querying db", err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+	}
+
+	var usage models.Usage
+	if result.Item == nil {
+		return &usage, nil
+	}
+	err = attributevalue.UnmarshalMap(result.Item, &usage)
+	if err != nil {
+		d.Log.For(ctx).Error("error unmarshaling db response", err)
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error unmarshaling db response"))
+	}
+
+	return &usage, nil
+}
+
+// ReserveUsage atomically adds one image of the given size to the user's usage,
+// failing with QuotaExceeded if that would take the user over the quota
+func (d *DynamoDBRepo) ReserveUsage(ctx context.Context, uID string, size int64, quota models.Quota) error {
+	if size > quota.MaxBytes {
+		return errors.New(errors.ErrCodeQuotaExceeded, fmt.Errorf("storage quota exceeded"))
+	}
+
+	update := d.usageUpdate(uID, size, 1)
+	update.ConditionExpression = aws.String("(attribute_not_exists(UsedBytes) OR UsedBytes <= :maxUsedBytes) " +
+		"AND (attribute_not_exists(ImageCount) OR ImageCount < :maxImages)")
+	update.ExpressionAttributeValues[":maxUsedBytes"] = numberValue(quota.MaxBytes - size)
+	update.ExpressionAttributeValues[":maxImages"] = numberValue(quota.MaxImages)
+
+	_, err := d.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 update.TableName,
+		Key:                       update.Key,
+		ConditionExpression:       update.ConditionExpression,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		UpdateExpression:          update.UpdateExpression,
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return errors.New(errors.ErrCodeQuotaExceeded, fmt.Errorf("storage quota exceeded"))
+		}
+		d.Log.For(ctx).Errorf("error reserving usage of user %s. error %v", uID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing usage"))
+	}
+
+	return nil
+}
+
+// ReleaseUsage gives back a reservation of an image that could not be stored
+func (d *DynamoDBRepo) ReleaseUsage(ctx context.Context, uID string, size int64) error {
+	return d.addUsage(ctx, uID, -size, -1)
+}
+
+// addUsage adds size and count, negative to give them back, to the user's usage
+func (d *DynamoDBRepo) addUsage(ctx context.Context, uID string, size, count int64) error {
+	update := d.usageUpdate(uID, size, count)
+
+	_, err := d.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 update.TableName,
+		Key:                       update.Key,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		UpdateExpression:          update.UpdateExpression,
+	})
+	if err != nil {
+		d.Log.For(ctx).Errorf("error releasing usage of user %s. error %v", uID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error processing usage"))
+	}
+
+	return nil
+}
+
+// usageUpdate builds the atomic counter update of the user's usage, used on its own or within a transaction
+func (d *DynamoDBRepo) usageUpdate(uID string, size, count int64) *types.Update {
+	return &types.Update{
+		TableName: &d.TableName,
+		Key:       usageKey(uID),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":size":  numberValue(size),
+			":count": numberValue(count),
+		},
+		UpdateExpression: aws.String("ADD UsedBytes :size, ImageCount :count"),
+	}
+}
+
+func usageKey(uID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		HashKey:  &types.AttributeValueMemberS{Value: uID},
+		RangeKey: &types.AttributeValueMemberS{Value: UsageRangeKey},
+	}
+}
+
+func numberValue(n int64) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
+
+func isTransactionConditionFailed(err error) bool {
+	var tce *types.TransactionCanceledException
+	if !stderrors.As(err, &tce) {
+		return false
+	}
+	for _, r := range tce.CancellationReasons {
+		if aws.ToString(r.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
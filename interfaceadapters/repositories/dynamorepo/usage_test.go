@@ -0,0 +1,65 @@
+package dynamorepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *RepoTestSuite) TestShouldReserveAndReleaseUsageOnDelete() {
+
+	quota := models.Quota{MaxBytes: 100, MaxImages: 2}
+
+	err := s.repo.ReserveUsage(context.Background(), "444", 60, quota)
+	assert.Nil(s.T(), err)
+	err = s.repo.AddImage(context.Background(), &models.UserImage{
+		IsDeleted: false,
+		UserID:    "444",
+		ImageID:   "iiiiiii-1111111",
+		Path:      "story-image/444/iiiiiii-1111111.jpg",
+		TakenAt:   time.Now(),
+		UpdatedAt: time.Now(),
+		Size:      60,
+	})
+	assert.Nil(s.T(), err)
+
+	// over the byte quota
+	err = s.repo.ReserveUsage(context.Background(), "444", 50, quota)
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), errors.ErrCodeQuotaExceeded, err.(errors.Error).Code)
+
+	usage, err := s.repo.GetUsage(context.Background(), "444")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(60), usage.UsedBytes)
+	assert.Equal(s.T(), int64(1), usage.ImageCount)
+
+	err = s.repo.DeleteImage(context.Background(), "444", "iiiiiii-1111111")
+	assert.Nil(s.T(), err)
+
+	usage, err = s.repo.GetUsage(context.Background(), "444")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(0), usage.UsedBytes)
+	assert.Equal(s.T(), int64(0), usage.ImageCount)
+}
+
+func (s *RepoTestSuite) TestShouldNotReserveUsageOverImageCount() {
+
+	quota := models.Quota{MaxBytes: 100, MaxImages: 1}
+
+	err := s.repo.ReserveUsage(context.Background(), "333", 10, quota)
+	assert.Nil(s.T(), err)
+
+	err = s.repo.ReserveUsage(context.Background(), "333", 10, quota)
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), errors.ErrCodeQuotaExceeded, err.(errors.Error).Code)
+
+	err = s.repo.ReleaseUsage(context.Background(), "333", 10)
+	assert.Nil(s.T(), err)
+
+	usage, err := s.repo.GetUsage(context.Background(), "333")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(0), usage.ImageCount)
+}
@@ -0,0 +1,229 @@
+package fsrepo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	// SignedFileOpener opens the files behind the signed urls handed out by GetPresignedURL
+	SignedFileOpener interface {
+		OpenSigned(ctx context.Context, key, expires, signature string) (io.ReadCloser, error)
+	}
+
+	// FSRepo keeps the image files in a local directory, using the same keys as S3Repo
+	FSRepo struct {
+		log       *logger.Logger
+		root      string
+		directory string
+		secret    []byte
+	}
+)
+
+var (
+	// ErrInvalidSignature is returned for a local url that is expired, tampered with or unknown
+	ErrInvalidSignature = stderrors.New("invalid or expired signature")
+
+	_ s3repo.S3Handler = (*FSRepo)(nil)
+	_ SignedFileOpener = (*FSRepo)(nil)
+)
+
+const secretBytes = 32
+
+// New creates a new instance of FSRepo
+func New(l *logger.Logger, env *config.Env) *FSRepo {
+	secret := []byte(env.LocalStorageSecret)
+	if len(secret) == 0 {
+		// urls signed with a random secret stop working on restart, which is fine for dev
+		l.Warn("no local storage secret configured, signing local urls with a random secret")
+		secret = make([]byte, secretBytes)
+		if _, err := rand.Read(secret); err != nil {
+			l.Fatalf("could not generate local storage secret :: %v", err)
+		}
+	}
+
+	return &FSRepo{
+		log:       l,
+		root:      env.LocalStorageDir,
+		directory: env.S3Directory,
+		secret:    secret,
+	}
+}
+
+// Save writes the image to a temp file in the target directory and renames it in place,
+// so readers never see a partially written file
+func (r *FSRepo) Save(ctx context.Context, uID string, imageID uuid.UUID, ext string, d *[]byte) (string, error) {
+	key := r.getPath(uID, fmt.Sprintf("%s%s", imageID.String(), ext))
+	f := r.filePath(key)
+
+	if err := writeAtomic(f, *d); err != nil {
+		r.log.For(ctx).Errorf("error writing file %s :: %v", f, err)
+		return key, &s3repo.StorageError{Op: "Write", Key: key, Err: err}
+	}
+
+	return key, nil
+}
+
+func (r *FSRepo) Delete(ctx context.Context, uID, imageID string) error {
+	f := r.filePath(r.getPath(uID, imageID))
+
+	err := os.Remove(f)
+	if err != nil && !stderrors.Is(err, fs.ErrNotExist) {
+		r.log.For(ctx).Errorf("error deleting file %s :: %v", f, err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAll deletes every file of the user, the files that could not be deleted are listed in the result
+func (r *FSRepo) DeleteAll(ctx context.Context, uID string) (*s3repo.DeleteResult, error) {
+	dir := r.filePath(r.getPath(uID, ""))
+	result := &s3repo.DeleteResult{}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if stderrors.Is(err, fs.ErrNotExist) {
+			return result, nil
+		}
+		r.log.For(ctx).Errorf("error listing files in %s :: %v", dir, err)
+		return result, err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil && !stderrors.Is(err, fs.ErrNotExist) {
+			result.Failed = append(result.Failed, s3repo.FailedKey{
+				Key:     r.getPath(uID, e.Name()),
+				Code:    "RemoveFailed",
+				Message: err.Error(),
+			})
+			continue
+		}
+		result.Deleted++
+	}
+
+	for _, f := range result.Failed {
+		r.log.For(ctx).Errorf("error deleting file %s :: %s", f.Key, f.Message)
+	}
+
+	return result, nil
+}
+
+// Get opens the file with the given key, the caller must close the returned reader
+func (r *FSRepo) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("invalid key %s", key)
+	}
+
+	f, err := os.Open(r.filePath(key))
+	if err != nil {
+		r.log.For(ctx).Errorf("error opening file %s :: %v", key, err)
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// GetPresignedURL returns a service relative url to read the file, signed and valid for the expiry duration
+func (r *FSRepo) GetPresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("invalid key %s", key)
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	q := url.Values{}
+	q.Set(config.QueryParamExpires, expires)
+	q.Set(config.QueryParamSignature, r.sign(key, expires))
+
+	return fmt.Sprintf("%s/%s?%s", config.LocalFilesPath, key, q.Encode()), nil
+}
+
+// Ping checks that the storage directory exists or can be created
+func (r *FSRepo) Ping(_ context.Context) error {
+	return os.MkdirAll(r.root, 0o750)
+}
+
+// OpenSigned opens the file of a url from GetPresignedURL, if the signature matches and has not expired
+func (r *FSRepo) OpenSigned(ctx context.Context, key, expires, signature string) (io.ReadCloser, error) {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return nil, ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(r.sign(key, expires))) {
+		return nil, ErrInvalidSignature
+	}
+
+	return r.Get(ctx, key)
+}
+
+func (r *FSRepo) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// getPath lays the files out like S3Repo does its keys
+func (r *FSRepo) getPath(uID, imageID string) string {
+	return path.Join(r.directory, uID, imageID)
+}
+
+func (r *FSRepo) filePath(key string) string {
+	return filepath.Join(r.root, filepath.FromSlash(key))
+}
+
+// validKey rejects keys that would resolve outside the storage directory
+func validKey(key string) bool {
+	return key != "" && !path.IsAbs(key) && path.Clean(key) == key && key != ".." && !strings.HasPrefix(key, "../")
+}
+
+func writeAtomic(name string, data []byte) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// a no-op once the rename succeeded
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
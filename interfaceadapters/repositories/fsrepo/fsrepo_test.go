@@ -0,0 +1,107 @@
+package fsrepo
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRepo(t *testing.T) *FSRepo {
+	return New(logger.New(), &config.Env{
+		LocalStorageDir:    t.TempDir(),
+		LocalStorageSecret: "test-secret",
+		S3Directory:        "story-images",
+	})
+}
+
+func TestFSRepo_SaveAndGet(t *testing.T) {
+	repo := newTestRepo(t)
+	imageID := uuid.New()
+	data := []byte("image")
+
+	key, err := repo.Save(context.Background(), "11", imageID, ".jpg", &data)
+	assert.NoError(t, err)
+	assert.Equal(t, "story-images/11/"+imageID.String()+".jpg", key)
+
+	f, err := repo.Get(context.Background(), key)
+	assert.NoError(t, err)
+	defer f.Close()
+	b, _ := io.ReadAll(f)
+	assert.Equal(t, "image", string(b))
+
+	// no temp files are left behind
+	entries, _ := os.ReadDir(filepath.Join(repo.root, "story-images", "11"))
+	assert.Len(t, entries, 1)
+}
+
+func TestFSRepo_GetRejectsKeysOutsideTheRoot(t *testing.T) {
+	repo := newTestRepo(t)
+
+	for _, key := range []string{"../secret", "/etc/passwd", "story-images/../../secret", ""} {
+		_, err := repo.Get(context.Background(), key)
+		assert.Error(t, err, key)
+	}
+}
+
+func TestFSRepo_OpenSigned(t *testing.T) {
+	repo := newTestRepo(t)
+	data := []byte("image")
+	key, _ := repo.Save(context.Background(), "11", uuid.New(), ".jpg", &data)
+
+	signed, err := repo.GetPresignedURL(context.Background(), key, time.Minute)
+	assert.NoError(t, err)
+	u, _ := url.Parse(signed)
+	assert.Equal(t, config.LocalFilesPath+"/"+key, u.Path)
+	expires, signature := u.Query().Get(config.QueryParamExpires), u.Query().Get(config.QueryParamSignature)
+
+	f, err := repo.OpenSigned(context.Background(), key, expires, signature)
+	assert.NoError(t, err)
+	f.Close()
+
+	_, err = repo.OpenSigned(context.Background(), key, expires, strings.Repeat("0", len(signature)))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = repo.OpenSigned(context.Background(), "story-images/12/other.jpg", expires, signature)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	expired, _ := repo.GetPresignedURL(context.Background(), key, -time.Minute)
+	u, _ = url.Parse(expired)
+	_, err = repo.OpenSigned(context.Background(), key, u.Query().Get(config.QueryParamExpires), u.Query().Get(config.QueryParamSignature))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestFSRepo_DeleteAndDeleteAll(t *testing.T) {
+	repo := newTestRepo(t)
+	data := []byte("image")
+	first := uuid.New()
+	_, _ = repo.Save(context.Background(), "11", first, ".jpg", &data)
+	_, _ = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &data)
+	_, _ = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &data)
+	other, _ := repo.Save(context.Background(), "110", uuid.New(), ".jpg", &data)
+
+	assert.NoError(t, repo.Delete(context.Background(), "11", first.String()+".jpg"))
+	// deleting a missing file is not an error
+	assert.NoError(t, repo.Delete(context.Background(), "11", first.String()+".jpg"))
+
+	result, err := repo.DeleteAll(context.Background(), "11")
+	assert.NoError(t, err)
+	assert.NoError(t, result.Err())
+	assert.Equal(t, 2, result.Deleted)
+
+	_, err = repo.Get(context.Background(), other)
+	assert.NoError(t, err)
+
+	result, err = repo.DeleteAll(context.Background(), "12")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Deleted)
+}
@@ -0,0 +1,91 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+)
+
+func (m *MemoryRepo) CreateAlbum(ctx context.Context, req *models.Album) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.albums[req.UserID] == nil {
+		m.albums[req.UserID] = make(map[string]models.Album)
+	}
+	m.albums[req.UserID][req.AlbumID] = *req
+
+	return nil
+}
+
+func (m *MemoryRepo) GetAlbum(ctx context.Context, uID, albumID string) (*models.Album, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	album, ok := m.albums[uID][albumID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("album not found"))
+	}
+
+	return &album, nil
+}
+
+// ListAlbums returns the albums of the user ordered by album id, like the album table's range key
+func (m *MemoryRepo) ListAlbums(ctx context.Context, uID string) ([]models.Album, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	albums := make([]models.Album, 0, len(m.albums[uID]))
+	for _, a := range m.albums[uID] {
+		albums = append(albums, a)
+	}
+	sort.Slice(albums, func(i, j int) bool { return albums[i].AlbumID < albums[j].AlbumID })
+
+	return albums, nil
+}
+
+func (m *MemoryRepo) RenameAlbum(ctx context.Context, uID, albumID, name string) (*models.Album, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	album, ok := m.albums[uID][albumID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("album not found"))
+	}
+	album.Name = name
+	album.UpdatedAt = time.Now()
+	m.albums[uID][albumID] = album
+
+	return &album, nil
+}
+
+// DeleteAlbum removes the album from all its images and deletes the album itself
+func (m *MemoryRepo) DeleteAlbum(ctx context.Context, uID, albumID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.albums[uID][albumID]; !ok {
+		return errors.New(errors.ErrCodeNotFound, fmt.Errorf("album not found"))
+	}
+
+	for imgID, img := range m.images[uID] {
+		ids := make([]string, 0, len(img.AlbumIDs))
+		for _, id := range img.AlbumIDs {
+			if id != albumID {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			ids = nil
+		}
+		img.AlbumIDs = ids
+		m.images[uID][imgID] = img
+	}
+	delete(m.albums[uID], albumID)
+
+	return nil
+}
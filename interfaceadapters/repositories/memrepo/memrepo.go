@@ -0,0 +1,268 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+type (
+	// MemoryRepo keeps images, albums, usage and shares in memory with the semantics of DynamoDBRepo.
+	// It is meant for local development and tests, nothing survives a restart.
+	MemoryRepo struct {
+		mu     sync.RWMutex
+		images map[string]map[string]models.UserImage
+		albums map[string]map[string]models.Album
+		usage  map[string]models.Usage
+		shares map[string]models.Share
+		log    *logger.Logger
+	}
+)
+
+var _ dynamorepo.DataHandler = (*MemoryRepo)(nil)
+
+// New creates a new, empty instance of MemoryRepo
+func New(l *logger.Logger) *MemoryRepo {
+	return &MemoryRepo{
+		images: make(map[string]map[string]models.UserImage),
+		albums: make(map[string]map[string]models.Album),
+		usage:  make(map[string]models.Usage),
+		shares: make(map[string]models.Share),
+		log:    l,
+	}
+}
+
+// Ping always succeeds, the records live in the process
+func (m *MemoryRepo) Ping(_ context.Context) error {
+	return nil
+}
+
+func (m *MemoryRepo) AddImage(ctx context.Context, req *models.UserImage) error {
+	// dynamoDB rejects empty key attributes
+	if req.UserID == "" || req.ImageID == "" {
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error persisting image data"))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.images[req.UserID] == nil {
+		m.images[req.UserID] = make(map[string]models.UserImage)
+	}
+	m.images[req.UserID][req.ImageID] = copyImage(*req)
+
+	return nil
+}
+
+func (m *MemoryRepo) GetImage(ctx context.Context, uID, imgID string) (*models.UserImage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	img, ok := m.liveImage(uID, imgID)
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("image not found"))
+	}
+
+	return &img, nil
+}
+
+func (m *MemoryRepo) DeleteImage(ctx context.Context, uID, imgID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.liveImage(uID, imgID); !ok {
+		return errors.New(errors.ErrCodeNotFound, fmt.Errorf("image not found"))
+	}
+	m.softDelete(uID, imgID)
+
+	return nil
+}
+
+func (m *MemoryRepo) DeleteAllImages(ctx context.Context, uID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for imgID := range m.images[uID] {
+		m.softDelete(uID, imgID)
+	}
+
+	return nil
+}
+
+// GetAllImagesPaginated returns up to req.Limit live images of the user, newest first.
+// The cursor is the last returned image whenever the page is full, like dynamoDB's last evaluated key.
+func (m *MemoryRepo) GetAllImagesPaginated(ctx context.Context, req models.PaginatedInput) (*models.UserImageResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	images := m.sortedImages(req, false)
+
+	if req.LastImageID != "" && req.LastImageTakenAt != "" {
+		takenAt, err := time.Parse(time.RFC3339Nano, req.LastImageTakenAt)
+		if err != nil {
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error querying db"))
+		}
+		start := sort.Search(len(images), func(i int) bool {
+			return after(images[i], takenAt, req.LastImageID)
+		})
+		images = images[start:]
+	}
+
+	response := &models.UserImageResult{
+		UserImages: make([]models.UserImage, 0, req.Limit),
+	}
+	if req.Limit <= 0 {
+		return response, nil
+	}
+
+	if int32(len(images)) >= req.Limit {
+		images = images[:req.Limit]
+		last := images[len(images)-1]
+		response.Page.LastEvaluatedKey = map[string]string{
+			config.QueryParamLastKey:     last.ImageID,
+			config.QueryParamlastKeyDate: last.TakenAt.Format(time.RFC3339Nano),
+		}
+	}
+	response.UserImages = append(response.UserImages, images...)
+
+	return response, nil
+}
+
+// IterateImages calls fn for every live image of the user taken between the optional from and to, oldest first
+func (m *MemoryRepo) IterateImages(ctx context.Context, uID string, from, to *time.Time, fn func(ui *models.UserImage) error) error {
+	m.mu.RLock()
+	images := m.sortedImages(models.PaginatedInput{UserID: uID}, true)
+	m.mu.RUnlock()
+
+	for i := range images {
+		if !models.TakenBetween(images[i].TakenAt, from, to) {
+			continue
+		}
+		if err := fn(&images[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetImageTags replaces the tags of an image, an empty list removes all tags
+func (m *MemoryRepo) SetImageTags(ctx context.Context, uID, imgID string, tags []string) error {
+	return m.updateLiveImage(uID, imgID, func(img *models.UserImage) {
+		img.Tags = nil
+		if len(tags) > 0 {
+			img.Tags = slices.Clone(tags)
+		}
+	})
+}
+
+func (m *MemoryRepo) AddImageToAlbum(ctx context.Context, uID, imgID, albumID string) error {
+	return m.updateLiveImage(uID, imgID, func(img *models.UserImage) {
+		if !slices.Contains(img.AlbumIDs, albumID) {
+			img.AlbumIDs = append(img.AlbumIDs, albumID)
+			slices.Sort(img.AlbumIDs)
+		}
+	})
+}
+
+func (m *MemoryRepo) RemoveImageFromAlbum(ctx context.Context, uID, imgID, albumID string) error {
+	return m.updateLiveImage(uID, imgID, func(img *models.UserImage) {
+		img.AlbumIDs = slices.DeleteFunc(img.AlbumIDs, func(id string) bool { return id == albumID })
+		if len(img.AlbumIDs) == 0 {
+			img.AlbumIDs = nil
+		}
+	})
+}
+
+// updateLiveImage applies the update to an image that exists and is not soft deleted
+func (m *MemoryRepo) updateLiveImage(uID, imgID string, update func(img *models.UserImage)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	img, ok := m.liveImage(uID, imgID)
+	if !ok {
+		return errors.New(errors.ErrCodeNotFound, fmt.Errorf("image not found"))
+	}
+	update(&img)
+	m.images[uID][imgID] = img
+
+	return nil
+}
+
+// liveImage returns a copy of the image if it exists and is not soft deleted, the caller must hold the lock
+func (m *MemoryRepo) liveImage(uID, imgID string) (models.UserImage, bool) {
+	img, ok := m.images[uID][imgID]
+	if !ok || img.IsDeleted {
+		return models.UserImage{}, false
+	}
+	return copyImage(img), true
+}
+
+// softDelete marks a live image deleted and gives its size back to the usage, the caller must hold the lock
+func (m *MemoryRepo) softDelete(uID, imgID string) {
+	img, ok := m.images[uID][imgID]
+	if !ok || img.IsDeleted {
+		return
+	}
+
+	img.IsDeleted = true
+	img.UpdatedAt = time.Now()
+	// a deleted image no longer belongs to any album
+	img.AlbumIDs = nil
+	m.images[uID][imgID] = img
+
+	// images stored before usage tracking have no size and were never counted
+	if img.Size > 0 {
+		m.addUsage(uID, -img.Size, -1)
+	}
+}
+
+// sortedImages returns copies of the live images of the user matching the filters of req,
+// ordered by TakenAt like the TakenAt index, the caller must hold the lock
+func (m *MemoryRepo) sortedImages(req models.PaginatedInput, oldestFirst bool) []models.UserImage {
+	images := make([]models.UserImage, 0, len(m.images[req.UserID]))
+	for _, img := range m.images[req.UserID] {
+		if img.IsDeleted {
+			continue
+		}
+		if req.AlbumID != "" && !slices.Contains(img.AlbumIDs, req.AlbumID) {
+			continue
+		}
+		if req.Tag != "" && !slices.Contains(img.Tags, req.Tag) {
+			continue
+		}
+		images = append(images, copyImage(img))
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		return after(images[j], images[i].TakenAt, images[i].ImageID)
+	})
+	if oldestFirst {
+		slices.Reverse(images)
+	}
+
+	return images
+}
+
+// after reports if img comes after the given position in newest first order
+func after(img models.UserImage, takenAt time.Time, imgID string) bool {
+	if !img.TakenAt.Equal(takenAt) {
+		return img.TakenAt.Before(takenAt)
+	}
+	return img.ImageID < imgID
+}
+
+func copyImage(img models.UserImage) models.UserImage {
+	img.Tags = slices.Clone(img.Tags)
+	img.AlbumIDs = slices.Clone(img.AlbumIDs)
+	return img
+}
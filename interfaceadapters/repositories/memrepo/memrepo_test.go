@@ -0,0 +1,16 @@
+package memrepo
+
+import (
+	"testing"
+
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo/dynamorepotest"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMemoryRepoContract(t *testing.T) {
+	suite.Run(t, &dynamorepotest.ContractSuite{
+		NewRepo: func() dynamorepo.DataHandler { return New(logger.New()) },
+	})
+}
@@ -0,0 +1,68 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+)
+
+func (m *MemoryRepo) CreateShare(ctx context.Context, req *models.Share) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shares[req.ShareID] = *req
+
+	return nil
+}
+
+func (m *MemoryRepo) GetShare(ctx context.Context, shareID string) (*models.Share, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	share, ok := m.shares[shareID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("share not found"))
+	}
+
+	return &share, nil
+}
+
+// ListShares returns the shares created by the owner, only those of one image if imgID is set,
+// ordered by image id like the owner index
+func (m *MemoryRepo) ListShares(ctx context.Context, ownerID, imgID string) ([]models.Share, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	shares := make([]models.Share, 0)
+	for _, sh := range m.shares {
+		if sh.OwnerID != ownerID || (imgID != "" && sh.ImageID != imgID) {
+			continue
+		}
+		shares = append(shares, sh)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].ImageID != shares[j].ImageID {
+			return shares[i].ImageID < shares[j].ImageID
+		}
+		return shares[i].ShareID < shares[j].ShareID
+	})
+
+	return shares, nil
+}
+
+// DeleteShare revokes a share, only if it was created by the owner
+func (m *MemoryRepo) DeleteShare(ctx context.Context, ownerID, shareID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	share, ok := m.shares[shareID]
+	if !ok || share.OwnerID != ownerID {
+		return errors.New(errors.ErrCodeNotFound, fmt.Errorf("share not found"))
+	}
+	delete(m.shares, shareID)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+)
+
+func (m *MemoryRepo) GetUsage(ctx context.Context, uID string) (*models.Usage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usage := m.usage[uID]
+	return &usage, nil
+}
+
+// ReserveUsage adds one image of the given size to the user's usage,
+// failing with QuotaExceeded if that would take the user over the quota
+func (m *MemoryRepo) ReserveUsage(ctx context.Context, uID string, size int64, quota models.Quota) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := m.usage[uID]
+	if usage.UsedBytes+size > quota.MaxBytes || usage.ImageCount >= quota.MaxImages {
+		return errors.New(errors.ErrCodeQuotaExceeded, fmt.Errorf("storage quota exceeded"))
+	}
+	m.addUsage(uID, size, 1)
+
+	return nil
+}
+
+// ReleaseUsage gives back a reservation of an image that could not be stored
+func (m *MemoryRepo) ReleaseUsage(ctx context.Context, uID string, size int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.addUsage(uID, -size, -1)
+
+	return nil
+}
+
+// addUsage changes the usage counters of the user, the caller must hold the lock
+func (m *MemoryRepo) addUsage(uID string, size, count int64) {
+	usage := m.usage[uID]
+	usage.UsedBytes += size
+	usage.ImageCount += count
+	m.usage[uID] = usage
+}
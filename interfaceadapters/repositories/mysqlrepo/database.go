@@ -1,6 +1,7 @@
 package mysqlrepo
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/rahul-aut-ind/service-user/domain/errors"
 	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/infrastructure/migrations"
 	"github.com/rahul-aut-ind/service-user/internal/config"
 	"github.com/rahul-aut-ind/service-user/pkg/logger"
 	"gorm.io/driver/mysql"
@@ -18,16 +20,19 @@ import (
 
 type (
 	DataHandler interface {
-		ListRecords() ([]models.User, error)
-		FindRecord(id string) (*models.User, error)
-		CreateRecord(u *models.User) (*models.User, error)
-		UpdateRecord(u *models.User) (*models.User, error)
-		DeleteRecord(u *models.User) (*models.User, error)
+		ListRecords(ctx context.Context) ([]models.User, error)
+		FindRecord(ctx context.Context, id string) (*models.User, error)
+		CreateRecord(ctx context.Context, u *models.User) (*models.User, error)
+		UpdateRecord(ctx context.Context, u *models.User) (*models.User, error)
+		DeleteRecord(ctx context.Context, u *models.User) (*models.User, error)
 	}
 
-	MysqlClient struct {
-		client *gorm.DB
-		log    *logger.Logger
+	// GormClient handles the user records in any database gorm has a dialector for
+	GormClient struct {
+		client   *gorm.DB
+		replicas *replicaSet
+		timeout  time.Duration
+		log      *logger.Logger
 	}
 )
 
@@ -35,28 +40,109 @@ const (
 	LogLevel = glog.Warn
 )
 
-func New(l *logger.Logger, env *config.Env) *MysqlClient {
-	return &MysqlClient{client: connect(env.DBConnectionString), log: l}
+// New connects to the MySQL database of the connection string and its read replicas
+func New(l *logger.Logger, env *config.Env) *GormClient {
+	return NewRouted(Open, env, l)
 }
 
-// Connect initializes the database connection
-func connect(dsn string) *gorm.DB {
-	client, err := gorm.Open(mysql.Open(dsn), initConfig())
+// Dialector opens the MySQL database of the connection string
+func Dialector(env *config.Env) gorm.Dialector {
+	return Open(env.DBConnectionString)
+}
+
+// Open opens the MySQL database of a DSN with or without the mysql:// scheme
+func Open(dsn string) gorm.Dialector {
+	return mysql.Open(config.TrimDBScheme(dsn))
+}
+
+// NewGormClient creates a GormClient on an open connection, bounding every query by timeout
+func NewGormClient(db *gorm.DB, timeout time.Duration, l *logger.Logger) *GormClient {
+	return &GormClient{client: db, timeout: timeout, log: l}
+}
+
+// Primary returns a handler sending all queries to the primary
+func (db *GormClient) Primary() DataHandler {
+	if db.replicas == nil {
+		return db
+	}
+	return &GormClient{client: db.client, timeout: db.timeout, log: db.log}
+}
+
+// Close stops the replica health checks and closes all connection pools
+func (db *GormClient) Close() error {
+	if db.replicas != nil {
+		db.replicas.close()
+	}
+	sqlDB, err := db.client.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Ping checks that the primary answers, replicas are not needed to serve requests
+func (db *GormClient) Ping(ctx context.Context) error {
+	sqlDB, err := db.client.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// withTimeout bounds a query by the timeout of the client
+func (db *GormClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, db.timeout)
+}
+
+// reader returns a healthy replica to read from, falling back to the primary
+func (db *GormClient) reader() *gorm.DB {
+	if db.replicas != nil {
+		if replica := db.replicas.pick(); replica != nil {
+			return replica
+		}
+	}
+	return db.client
+}
+
+// Connect initializes the database connection through the dialector of the database,
+// applying the pending schema migrations if autoMigrate is set. It panics if that fails, see Dial.
+func Connect(dialector gorm.Dialector, autoMigrate bool, l *logger.Logger) *gorm.DB {
+	client, err := Dial(dialector, autoMigrate, l)
 	if err != nil {
-		panic(fmt.Sprintf("failed to connect to database :: %v", err))
+		panic(err.Error())
 	}
+	return client
+}
 
-	// Auto-migrate the User model
-	err = client.AutoMigrate(&models.User{})
+// Dial is Connect returning the error instead of panicking, for reconnecting a running service
+func Dial(dialector gorm.Dialector, autoMigrate bool, l *logger.Logger) (*gorm.DB, error) {
+	client, err := gorm.Open(dialector, initConfig())
 	if err != nil {
-		panic(fmt.Sprintf("could not initialize tables | err :: %v", err))
+		return nil, fmt.Errorf("failed to connect to database :: %w", err)
 	}
 
 	sqlDB, _ := client.DB()
 	sqlDB.SetMaxIdleConns(5)
 	sqlDB.SetMaxOpenConns(5)
 
-	return client
+	if autoMigrate {
+		m, err := migrations.New(sqlDB, dialector.Name(), l)
+		if err != nil {
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("could not load migrations | err :: %w", err)
+		}
+		if _, err := m.Up(context.Background()); err != nil {
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("could not initialize tables | err :: %w", err)
+		}
+	}
+
+	return client, nil
 }
 
 // initConfig Initialize Config
@@ -85,19 +171,23 @@ func initNamingStrategy() *schema.NamingStrategy {
 	}
 }
 
-func (db *MysqlClient) CreateRecord(u *models.User) (*models.User, error) {
-	db.log.Debugf("inserting record %v", *u)
-	result := db.client.Create(&u)
+func (db *GormClient) CreateRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	db.log.For(ctx).Debugf("inserting record %v", *u)
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+	result := db.client.WithContext(ctx).Create(&u)
 	if result.Error != nil {
 		return nil, fmt.Errorf("err :: %v", result.Error)
 	}
 	return u, nil
 }
 
-func (db *MysqlClient) FindRecord(id string) (*models.User, error) {
-	db.log.Debugf("finding record with id %s", id)
+func (db *GormClient) FindRecord(ctx context.Context, id string) (*models.User, error) {
+	db.log.For(ctx).Debugf("finding record with id %s", id)
 	var user models.User
-	result := db.client.Where(id).First(&user)
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+	result := db.reader().WithContext(ctx).Where(id).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("err :: %v", errors.ErrCodeNoUser)
@@ -107,28 +197,34 @@ func (db *MysqlClient) FindRecord(id string) (*models.User, error) {
 	return &user, nil
 }
 
-func (db *MysqlClient) DeleteRecord(u *models.User) (*models.User, error) {
-	db.log.Debugf("deleting record with id %d", u.ID)
-	result := db.client.Delete(&u)
+func (db *GormClient) DeleteRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	db.log.For(ctx).Debugf("deleting record with id %d", u.ID)
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+	result := db.client.WithContext(ctx).Delete(&u)
 	if result.Error != nil {
 		return nil, fmt.Errorf("err :: %v", result.Error)
 	}
 	return u, nil
 }
 
-func (db *MysqlClient) ListRecords() ([]models.User, error) {
-	db.log.Debugf("listing all records")
+func (db *GormClient) ListRecords(ctx context.Context) ([]models.User, error) {
+	db.log.For(ctx).Debugf("listing all records")
 	var users []models.User
-	result := db.client.Find(&users)
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+	result := db.reader().WithContext(ctx).Find(&users)
 	if result.Error != nil {
 		return nil, fmt.Errorf("err :: %v", result.Error)
 	}
 	return users, nil
 }
 
-func (db *MysqlClient) UpdateRecord(u *models.User) (*models.User, error) {
-	db.log.Debugf("updating record with id %d", u.ID)
-	result := db.client.Updates(&u)
+func (db *GormClient) UpdateRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	db.log.For(ctx).Debugf("updating record with id %d", u.ID)
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+	result := db.client.WithContext(ctx).Updates(&u)
 	if result.Error != nil {
 		return nil, fmt.Errorf("err :: %v", result.Error)
 	}
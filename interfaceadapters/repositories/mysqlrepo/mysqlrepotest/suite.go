@@ -0,0 +1,102 @@
+// Package mysqlrepotest holds the tests every mysqlrepo.DataHandler implementation has to pass
+package mysqlrepotest
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// UserRepoSuite runs against the repo returned by NewRepo, once per database
+type UserRepoSuite struct {
+	suite.Suite
+	NewRepo func() mysqlrepo.DataHandler
+	repo    mysqlrepo.DataHandler
+}
+
+func (s *UserRepoSuite) SetupTest() {
+	s.repo = s.NewRepo()
+}
+
+func (s *UserRepoSuite) TestShouldCreateUser() {
+	res, err := s.repo.CreateRecord(context.Background(), &models.User{
+		Name:  "test1",
+		Email: "test1@test.com",
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "test1", res.Name)
+	assert.Equal(s.T(), "test1@test.com", res.Email)
+}
+
+func (s *UserRepoSuite) TestShouldGetUser() {
+	res, _ := s.repo.CreateRecord(context.Background(), &models.User{
+		Name:  "test2",
+		Email: "test2@test.com",
+	})
+	u, err := s.repo.FindRecord(context.Background(), strconv.Itoa(int(res.ID)))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "test2", u.Name)
+	assert.Equal(s.T(), "test2@test.com", u.Email)
+}
+
+func (s *UserRepoSuite) TestShouldUpdateUser() {
+	res, _ := s.repo.CreateRecord(context.Background(), &models.User{
+		Name:  "test3",
+		Email: "test3@test.com",
+	})
+
+	u, err := s.repo.UpdateRecord(context.Background(), &models.User{
+		ID:    res.ID,
+		Name:  "test3_updated",
+		Email: "test3@test.com",
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "test3_updated", u.Name)
+	assert.Equal(s.T(), "test3@test.com", u.Email)
+}
+
+func (s *UserRepoSuite) TestShouldDeleteUser() {
+	res, _ := s.repo.CreateRecord(context.Background(), &models.User{
+		Name:  "test4",
+		Email: "test4@test.com",
+	})
+
+	_, err := s.repo.DeleteRecord(context.Background(), &models.User{
+		ID: res.ID,
+	})
+
+	assert.Nil(s.T(), err)
+}
+
+func (s *UserRepoSuite) TestShouldGetAllUsers() {
+	res1, _ := s.repo.CreateRecord(context.Background(), &models.User{
+		Name:  "test5",
+		Email: "test5@test.com",
+	})
+	res2, _ := s.repo.CreateRecord(context.Background(), &models.User{
+		Name:  "test6",
+		Email: "test6@test.com",
+	})
+
+	records, err := s.repo.ListRecords(context.Background())
+
+	assert.Nil(s.T(), err)
+
+	results := make([]string, 0, len(records))
+	for _, i := range records {
+		results = append(results, i.Email, i.Name)
+	}
+
+	assert.Contains(s.T(), results, res1.Email)
+	assert.Contains(s.T(), results, res1.Name)
+	assert.Contains(s.T(), results, res2.Email)
+	assert.Contains(s.T(), results, res2.Name)
+
+}
@@ -0,0 +1,270 @@
+package mysqlrepo
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type (
+	// PrimaryPinner is implemented by handlers sending reads to replicas.
+	// Primary returns a handler reading from the primary too, for reads a following write depends on.
+	PrimaryPinner interface {
+		Primary() DataHandler
+	}
+
+	// replica is one read replica, healthy while it is reachable and its lag is within the max lag
+	replica struct {
+		name    string
+		db      *gorm.DB
+		healthy atomic.Bool
+	}
+
+	// replicaSet balances reads over the healthy replicas, ejecting lagging ones on every check
+	replicaSet struct {
+		replicas []*replica
+		maxLag   time.Duration
+		next     atomic.Uint64
+		lag      func(ctx context.Context, db *sql.DB) (time.Duration, error)
+		log      *logger.Logger
+		stop     chan struct{}
+		stopOnce sync.Once
+	}
+)
+
+const lagCheckTimeout = 2 * time.Second
+
+// NewRouted connects through open to the primary and the read replicas of the config.
+// Reads go to a healthy replica, writes and reads of a pinned handler to the primary,
+// see PrimaryPinner. Without replicas every query goes to the primary. It panics if connecting fails, see DialRouted.
+func NewRouted(open func(dsn string) gorm.Dialector, env *config.Env, l *logger.Logger) *GormClient {
+	client, err := DialRouted(open, env, l)
+	if err != nil {
+		panic(err.Error())
+	}
+	return client
+}
+
+// DialRouted is NewRouted returning the error instead of panicking, for reconnecting a running service
+func DialRouted(open func(dsn string) gorm.Dialector, env *config.Env, l *logger.Logger) (*GormClient, error) {
+	primary, err := Dial(open(env.DBConnectionString), env.AutoMigrate, l)
+	if err != nil {
+		return nil, err
+	}
+	setPool(primary, env.DBPrimaryPool)
+
+	client := NewGormClient(primary, env.DBTimeout, l)
+	if len(env.DBReplicaConnectionStrings) == 0 {
+		return client, nil
+	}
+
+	replicas := make([]*replica, 0, len(env.DBReplicaConnectionStrings))
+	for i, dsn := range env.DBReplicaConnectionStrings {
+		db, err := connectReplica(open(dsn))
+		if err != nil {
+			client.replicas = newReplicaSet(replicas, env.DBReplicaMaxLag, nil, l)
+			return nil, stderrors.Join(err, client.Close())
+		}
+		setPool(db, env.DBReplicaPool)
+		replicas = append(replicas, &replica{name: "replica-" + strconv.Itoa(i), db: db})
+	}
+
+	client.replicas = newReplicaSet(replicas, env.DBReplicaMaxLag, lagQuery(primary.Dialector.Name()), l)
+	client.replicas.check()
+	go client.replicas.watch(env.DBReplicaCheckInterval)
+
+	return client, nil
+}
+
+// connectReplica opens a replica without pinging it, an unreachable replica starts ejected
+// instead of failing the boot
+func connectReplica(dialector gorm.Dialector) (*gorm.DB, error) {
+	cfg := initConfig()
+	cfg.DisableAutomaticPing = true
+	db, err := gorm.Open(dialector, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replica :: %w", err)
+	}
+	return db, nil
+}
+
+func setPool(db *gorm.DB, pool config.Pool) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+}
+
+func newReplicaSet(replicas []*replica, maxLag time.Duration,
+	lag func(ctx context.Context, db *sql.DB) (time.Duration, error), l *logger.Logger) *replicaSet {
+	return &replicaSet{replicas: replicas, maxLag: maxLag, lag: lag, log: l, stop: make(chan struct{})}
+}
+
+// pick returns the next healthy replica round robin, nil if none is healthy
+func (rs *replicaSet) pick() *gorm.DB {
+	healthy := 0
+	for _, r := range rs.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	if healthy == 0 {
+		return nil
+	}
+
+	k := int(rs.next.Add(1) % uint64(healthy))
+	for _, r := range rs.replicas {
+		if !r.healthy.Load() {
+			continue
+		}
+		if k == 0 {
+			return r.db
+		}
+		k--
+	}
+	// a replica was ejected in between, the next read picks again
+	return nil
+}
+
+// check measures the lag of every replica, ejecting the unreachable and lagging ones
+// and bringing back the ones that caught up
+func (rs *replicaSet) check() {
+	for _, r := range rs.replicas {
+		healthy := rs.checkReplica(r)
+		if was := r.healthy.Swap(healthy); was != healthy {
+			if healthy {
+				rs.log.Infof("replica %s is back in rotation", r.name)
+			} else {
+				rs.log.Warnf("replica %s ejected from rotation", r.name)
+			}
+		}
+	}
+}
+
+func (rs *replicaSet) checkReplica(r *replica) bool {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		rs.log.Errorf("replica %s has no connection pool :: %v", r.name, err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lagCheckTimeout)
+	defer cancel()
+
+	lag, err := rs.lag(ctx, sqlDB)
+	if err != nil {
+		rs.log.Warnf("replica %s failed its health check :: %v", r.name, err)
+		return false
+	}
+	if lag > rs.maxLag {
+		rs.log.Warnf("replica %s lags %s behind the primary, max is %s", r.name, lag, rs.maxLag)
+		return false
+	}
+	return true
+}
+
+// watch checks the replicas every interval until close
+func (rs *replicaSet) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rs.check()
+		case <-rs.stop:
+			return
+		}
+	}
+}
+
+// close stops the health checks and closes the replica pools
+func (rs *replicaSet) close() {
+	rs.stopOnce.Do(func() {
+		close(rs.stop)
+		for _, r := range rs.replicas {
+			if sqlDB, err := r.db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		}
+	})
+}
+
+// lagQuery returns how the replication lag is read for the gorm dialect name,
+// dialects without replication only need to answer a ping
+func lagQuery(dialect string) func(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	switch dialect {
+	case config.DBDriverMySQL:
+		return mysqlLag
+	case config.DBDriverPostgres:
+		return postgresLag
+	default:
+		return func(ctx context.Context, db *sql.DB) (time.Duration, error) {
+			return 0, db.PingContext(ctx)
+		}
+	}
+}
+
+// mysqlLag reads Seconds_Behind_Source from SHOW REPLICA STATUS.
+// A server that is no replica has no status row and no lag, a stopped replication reports NULL.
+func mysqlLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" {
+			continue
+		}
+		if values[i] == nil {
+			return 0, fmt.Errorf("replication is not running")
+		}
+		seconds, err := strconv.ParseInt(string(values[i]), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid Seconds_Behind_Source %q :: %v", values[i], err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return 0, fmt.Errorf("no Seconds_Behind_Source in replica status")
+}
+
+// postgresLag reads the age of the last replayed transaction, zero on a primary
+func postgresLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var seconds float64
+	err := db.QueryRowContext(ctx,
+		"SELECT COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)").Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
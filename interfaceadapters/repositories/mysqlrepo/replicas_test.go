@@ -0,0 +1,128 @@
+package mysqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeLag reports the lag or error stored for it, shared by all replicas
+type fakeLag struct {
+	lag atomic.Int64
+	err atomic.Pointer[error]
+}
+
+func (f *fakeLag) query(context.Context, *sql.DB) (time.Duration, error) {
+	if err := f.err.Load(); err != nil {
+		return 0, *err
+	}
+	return time.Duration(f.lag.Load()), nil
+}
+
+func openTestDB(t *testing.T, name string) *gorm.DB {
+	return Connect(sqlite.Open(filepath.Join(t.TempDir(), name)), true, logger.New())
+}
+
+// newRoutedTestClient returns a client whose primary and replica hold a user with the same id
+// but different names, telling apart where a read went
+func newRoutedTestClient(t *testing.T, lag *fakeLag) (*GormClient, string) {
+	primary := openTestDB(t, "primary.db")
+	replicaDB := openTestDB(t, "replica.db")
+
+	u := &models.User{Name: "on-primary", Email: "routed@test.com"}
+	require.NoError(t, primary.Create(u).Error)
+	require.NoError(t, replicaDB.Create(&models.User{ID: u.ID, Name: "on-replica", Email: "routed@test.com"}).Error)
+
+	client := NewGormClient(primary, time.Second, logger.New())
+	client.replicas = newReplicaSet([]*replica{{name: "replica-0", db: replicaDB}}, time.Second, lag.query, logger.New())
+	client.replicas.check()
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client, strconv.Itoa(int(u.ID))
+}
+
+func TestShouldReadFromHealthyReplica(t *testing.T) {
+	client, id := newRoutedTestClient(t, &fakeLag{})
+
+	u, err := client.FindRecord(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "on-replica", u.Name)
+
+	users, err := client.ListRecords(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "on-replica", users[0].Name)
+}
+
+func TestShouldReadFromPrimaryWhenPinned(t *testing.T) {
+	client, id := newRoutedTestClient(t, &fakeLag{})
+
+	u, err := client.Primary().FindRecord(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "on-primary", u.Name)
+}
+
+func TestShouldEjectLaggingReplica(t *testing.T) {
+	lag := &fakeLag{}
+	client, id := newRoutedTestClient(t, lag)
+
+	lag.lag.Store(int64(time.Minute))
+	client.replicas.check()
+
+	u, err := client.FindRecord(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "on-primary", u.Name)
+
+	lag.lag.Store(0)
+	client.replicas.check()
+
+	u, err = client.FindRecord(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "on-replica", u.Name)
+}
+
+func TestShouldEjectUnreachableReplica(t *testing.T) {
+	lag := &fakeLag{}
+	client, id := newRoutedTestClient(t, lag)
+
+	err := errors.New("connection refused")
+	lag.err.Store(&err)
+	client.replicas.check()
+
+	u, findErr := client.FindRecord(context.Background(), id)
+	require.NoError(t, findErr)
+	assert.Equal(t, "on-primary", u.Name)
+}
+
+func TestShouldBalanceOverHealthyReplicas(t *testing.T) {
+	a, b, c := &replica{name: "a"}, &replica{name: "b"}, &replica{name: "c"}
+	a.db, b.db, c.db = &gorm.DB{}, &gorm.DB{}, &gorm.DB{}
+	a.healthy.Store(true)
+	c.healthy.Store(true)
+	rs := newReplicaSet([]*replica{a, b, c}, time.Second, nil, logger.New())
+
+	seen := map[*gorm.DB]int{}
+	for i := 0; i < 10; i++ {
+		seen[rs.pick()]++
+	}
+
+	assert.Equal(t, 5, seen[a.db])
+	assert.Equal(t, 0, seen[b.db])
+	assert.Equal(t, 5, seen[c.db])
+
+	a.healthy.Store(false)
+	c.healthy.Store(false)
+	assert.Nil(t, rs.pick())
+}
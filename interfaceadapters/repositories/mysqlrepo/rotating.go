@@ -0,0 +1,107 @@
+package mysqlrepo
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+)
+
+// Rotating serves the user records from a client whose connection pools are rebuilt when the credentials
+// of the connection strings rotate, see Reconnect
+type Rotating struct {
+	current atomic.Pointer[GormClient]
+	dial    func(env *config.Env) (*GormClient, error)
+	drain   time.Duration
+	log     *logger.Logger
+
+	mu      sync.Mutex
+	retired map[*GormClient]*time.Timer
+}
+
+// DrainDelay is how long a replaced client stays open, so the queries of requests holding it can finish
+const DrainDelay = time.Minute
+
+var (
+	_ DataHandler   = (*Rotating)(nil)
+	_ PrimaryPinner = (*Rotating)(nil)
+)
+
+// NewRotating serves from client until Reconnect replaces it with a client from dial
+func NewRotating(client *GormClient, dial func(env *config.Env) (*GormClient, error), l *logger.Logger) *Rotating {
+	r := &Rotating{dial: dial, drain: DrainDelay, log: l, retired: map[*GormClient]*time.Timer{}}
+	r.current.Store(client)
+	return r
+}
+
+// Reconnect connects with the connection strings of env and swaps the new pools in. The old ones are closed
+// after the DrainDelay. If connecting fails the old pools keep serving.
+func (r *Rotating) Reconnect(_ context.Context, env *config.Env) error {
+	next, err := r.dial(env)
+	if err != nil {
+		return err
+	}
+	old := r.current.Swap(next)
+	r.log.Infof("user database reconnected, closing the previous connection pools in %s", r.drain)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retired[old] = time.AfterFunc(r.drain, func() {
+		r.mu.Lock()
+		delete(r.retired, old)
+		r.mu.Unlock()
+		if err := old.Close(); err != nil {
+			r.log.Warnf("could not close the previous user database pools :: %v", err)
+		}
+	})
+	return nil
+}
+
+// Close closes the current and the draining connection pools
+func (r *Rotating) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := []error{r.current.Load().Close()}
+	for old, timer := range r.retired {
+		timer.Stop()
+		errs = append(errs, old.Close())
+	}
+	clear(r.retired)
+	return stderrors.Join(errs...)
+}
+
+// Ping checks the primary of the current client
+func (r *Rotating) Ping(ctx context.Context) error {
+	return r.current.Load().Ping(ctx)
+}
+
+// Primary returns a handler reading from the primary of the current client
+func (r *Rotating) Primary() DataHandler {
+	return r.current.Load().Primary()
+}
+
+func (r *Rotating) ListRecords(ctx context.Context) ([]models.User, error) {
+	return r.current.Load().ListRecords(ctx)
+}
+
+func (r *Rotating) FindRecord(ctx context.Context, id string) (*models.User, error) {
+	return r.current.Load().FindRecord(ctx, id)
+}
+
+func (r *Rotating) CreateRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	return r.current.Load().CreateRecord(ctx, u)
+}
+
+func (r *Rotating) UpdateRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	return r.current.Load().UpdateRecord(ctx, u)
+}
+
+func (r *Rotating) DeleteRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	return r.current.Load().DeleteRecord(ctx, u)
+}
@@ -0,0 +1,76 @@
+package mysqlrepo
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRotatingTestDB returns a Rotating client on a SQLite database per connection string
+func newRotatingTestDB(t *testing.T) *Rotating {
+	dir := t.TempDir()
+	dial := func(env *config.Env) (*GormClient, error) {
+		if env.DBConnectionString == "" {
+			return nil, errors.New("no connection string")
+		}
+		db, err := Dial(sqlite.Open(filepath.Join(dir, env.DBConnectionString)), true, logger.New())
+		if err != nil {
+			return nil, err
+		}
+		return NewGormClient(db, time.Second, logger.New()), nil
+	}
+
+	first, err := dial(&config.Env{DBConnectionString: "first.db"})
+	require.NoError(t, err)
+	r := NewRotating(first, dial, logger.New())
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestRotating_ReconnectSwapsPools(t *testing.T) {
+	r := newRotatingTestDB(t)
+	ctx := context.Background()
+	_, err := r.CreateRecord(ctx, &models.User{Name: "before", Email: "before@test.com"})
+	require.NoError(t, err)
+	old := r.current.Load()
+
+	require.NoError(t, r.Reconnect(ctx, &config.Env{DBConnectionString: "second.db"}))
+
+	users, err := r.ListRecords(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, users, "reads go to the new database")
+	assert.NoError(t, old.Ping(ctx), "the old pools drain before they are closed")
+
+	require.NoError(t, r.Close())
+	assert.Error(t, old.Ping(ctx), "closing closes the draining pools too")
+}
+
+func TestRotating_ClosesOldPoolsAfterDrain(t *testing.T) {
+	r := newRotatingTestDB(t)
+	r.drain = 10 * time.Millisecond
+	old := r.current.Load()
+
+	require.NoError(t, r.Reconnect(context.Background(), &config.Env{DBConnectionString: "second.db"}))
+
+	assert.Eventually(t, func() bool { return old.Ping(context.Background()) != nil }, time.Second, 5*time.Millisecond)
+}
+
+func TestRotating_KeepsPoolsWhenReconnectFails(t *testing.T) {
+	r := newRotatingTestDB(t)
+	old := r.current.Load()
+
+	err := r.Reconnect(context.Background(), &config.Env{})
+
+	assert.Error(t, err)
+	assert.Same(t, old, r.current.Load())
+	assert.NoError(t, r.Ping(context.Background()))
+}
@@ -0,0 +1,25 @@
+package postgresrepo
+
+import (
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// New connects to the PostgreSQL database of the connection string and its read replicas.
+// The records are handled by the same gorm client as with MySQL, only the dialector differs.
+func New(l *logger.Logger, env *config.Env) *mysqlrepo.GormClient {
+	return mysqlrepo.NewRouted(Open, env, l)
+}
+
+// Dialector opens the PostgreSQL database of the connection string
+func Dialector(env *config.Env) gorm.Dialector {
+	return Open(env.DBConnectionString)
+}
+
+// Open opens the PostgreSQL database of a postgres:// DSN
+func Open(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
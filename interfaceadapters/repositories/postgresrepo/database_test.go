@@ -0,0 +1,32 @@
+package postgresrepo
+
+import (
+	"testing"
+
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/integrationtest"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo/mysqlrepotest"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepoTestSuite struct {
+	mysqlrepotest.UserRepoSuite
+	dbSetup *integrationtest.PostgresSetup
+}
+
+func (s *RepoTestSuite) SetupSuite() {
+	s.dbSetup = integrationtest.NewPostgresSetup()
+	s.NewRepo = func() mysqlrepo.DataHandler {
+		return New(logger.New(), &config.Env{DBConnectionString: s.dbSetup.ConnString, AutoMigrate: true})
+	}
+}
+
+func (s *RepoTestSuite) TearDownSuite() {
+	s.dbSetup.Stop()
+}
+
+func TestRepoSuite(t *testing.T) {
+	suite.Run(t, new(RepoTestSuite))
+}
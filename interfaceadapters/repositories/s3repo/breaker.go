@@ -0,0 +1,77 @@
+package s3repo
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// BreakerThreshold is the number of failed uploads in a row that opens the circuit
+	BreakerThreshold = 5
+	// BreakerCooldown is how long the circuit stays open before one upload may try again
+	BreakerCooldown = 30 * time.Second
+)
+
+// breaker is a circuit breaker that fails calls fast once S3 kept failing. After the cooldown
+// a single trial call is let through, which closes the circuit again if it succeeds.
+// A trial ending any other way opens the circuit for another cooldown.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool
+	now       func() time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may be made and whether it is the trial of an open circuit.
+// Every allowed call must be followed by release with the trial flag, whatever its outcome.
+func (b *breaker) allow() (ok, trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true, false
+	}
+	if b.trial || b.now().Before(b.openUntil) {
+		return false, false
+	}
+	b.trial = true
+	return true, true
+}
+
+// release ends a call, a trial not settled by success or failure reopens the circuit,
+// so the next call is tried after another cooldown instead of never
+func (b *breaker) release(trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial && b.trial {
+		b.trial = false
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
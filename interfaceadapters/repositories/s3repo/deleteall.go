@@ -0,0 +1,164 @@
+package s3repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+)
+
+type (
+	// DeleteResult reports the outcome of deleting many objects, per key
+	DeleteResult struct {
+		Deleted int
+		Failed  []FailedKey
+	}
+
+	// FailedKey is an object that could not be deleted after all retries
+	FailedKey struct {
+		Key     string
+		Code    string
+		Message string
+	}
+)
+
+const (
+	// MaxDeleteBatch is the most keys S3 accepts in one DeleteObjects call
+	MaxDeleteBatch = 1000
+	// DeleteConcurrency bounds the DeleteObjects calls in flight for one user
+	DeleteConcurrency = 4
+	// DeleteAttempts is how often a key is tried before it is reported as failed
+	DeleteAttempts = 3
+)
+
+// Err summarizes the failed keys as an error, nil if every key was deleted
+func (dr *DeleteResult) Err() error {
+	if len(dr.Failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d objects could not be deleted, first %s: %s %s",
+		len(dr.Failed), dr.Failed[0].Key, dr.Failed[0].Code, dr.Failed[0].Message)
+}
+
+// DeleteAll deletes every object under the user's prefix. The listing is paged, each page is
+// deleted as one batch, with a bounded number of batches in flight. Keys that S3 fails to delete
+// are retried and the ones still failing are listed in the result. The error is only set if
+// listing the objects failed, in which case the result covers the pages listed until then.
+func (r *S3Repo) DeleteAll(ctx context.Context, uID string) (*DeleteResult, error) {
+	// the trailing slash keeps user 11 from matching the objects of user 110
+	prefix := r.getPath(uID, "") + "/"
+	result := &DeleteResult{}
+	var mu sync.Mutex
+
+	g := &errgroup.Group{}
+	g.SetLimit(DeleteConcurrency)
+
+	paginator := s3.NewListObjectsV2Paginator(r.client, &s3.ListObjectsV2Input{
+		Bucket:  &r.bucket,
+		Prefix:  &prefix,
+		MaxKeys: aws.Int32(MaxDeleteBatch),
+	})
+
+	var listErr error
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			r.log.For(ctx).Errorf("error listing objects in bucket %s with prefix %s: %v", r.bucket, prefix, err)
+			listErr = err
+			break
+		}
+
+		for _, batch := range batchKeys(page.Contents) {
+			g.Go(func() error {
+				deleted, failed := r.deleteBatch(ctx, batch)
+				mu.Lock()
+				defer mu.Unlock()
+				result.Deleted += deleted
+				result.Failed = append(result.Failed, failed...)
+				return nil
+			})
+		}
+	}
+	_ = g.Wait()
+
+	for _, f := range result.Failed {
+		r.log.For(ctx).Errorf("error deleting object %s in bucket %s: %s %s", f.Key, r.bucket, f.Code, f.Message)
+	}
+
+	return result, listErr
+}
+
+// deleteBatch deletes up to MaxDeleteBatch keys, retrying the keys that fail with jittered backoff
+func (r *S3Repo) deleteBatch(ctx context.Context, keys []string) (deleted int, failed []FailedKey) {
+	pending := keys
+
+	for attempt := 1; ; attempt++ {
+		failed = r.deleteObjects(ctx, pending)
+		deleted += len(pending) - len(failed)
+		if len(failed) == 0 || attempt == DeleteAttempts {
+			return deleted, failed
+		}
+
+		r.log.For(ctx).Warnf("retrying delete of %d objects in bucket %s, attempt %d", len(failed), r.bucket, attempt+1)
+		if err := sleep(ctx, backoff(r.retryDelay, attempt-1)); err != nil {
+			return deleted, failed
+		}
+
+		pending = make([]string, 0, len(failed))
+		for _, f := range failed {
+			pending = append(pending, f.Key)
+		}
+	}
+}
+
+// deleteObjects makes one DeleteObjects call and returns the keys that were not deleted
+func (r *S3Repo) deleteObjects(ctx context.Context, keys []string) []FailedKey {
+	objects := make([]types.ObjectIdentifier, 0, len(keys))
+	for _, k := range keys {
+		objects = append(objects, types.ObjectIdentifier{Key: aws.String(k)})
+	}
+
+	out, err := r.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: &r.bucket,
+		Delete: &types.Delete{
+			Objects: objects,
+			// only report the keys that failed
+			Quiet: aws.Bool(true),
+		},
+	})
+	if err != nil {
+		failed := make([]FailedKey, 0, len(keys))
+		for _, k := range keys {
+			failed = append(failed, FailedKey{Key: k, Code: "RequestFailed", Message: err.Error()})
+		}
+		return failed
+	}
+
+	failed := make([]FailedKey, 0, len(out.Errors))
+	for _, e := range out.Errors {
+		failed = append(failed, FailedKey{
+			Key:     aws.ToString(e.Key),
+			Code:    aws.ToString(e.Code),
+			Message: aws.ToString(e.Message),
+		})
+	}
+	return failed
+}
+
+// batchKeys splits the listed objects into batches small enough for one DeleteObjects call
+func batchKeys(objects []types.Object) [][]string {
+	batches := make([][]string, 0, len(objects)/MaxDeleteBatch+1)
+	for start := 0; start < len(objects); start += MaxDeleteBatch {
+		end := min(start+MaxDeleteBatch, len(objects))
+		batch := make([]string, 0, end-start)
+		for _, o := range objects[start:end] {
+			batch = append(batch, aws.ToString(o.Key))
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,100 @@
+package s3repo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	// fakeS3 keeps objects in memory, keys in failOnce fail on their first delete, keys in failAlways never delete
+	fakeS3 struct {
+		s3Client
+		mu         sync.Mutex
+		keys       []string
+		failOnce   map[string]bool
+		failAlways map[string]bool
+		batchSizes []int
+	}
+)
+
+func (f *fakeS3) ListObjectsV2(_ context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	start := 0
+	if in.ContinuationToken != nil {
+		start, _ = strconv.Atoi(*in.ContinuationToken)
+	}
+	end := min(start+int(aws.ToInt32(in.MaxKeys)), len(f.keys))
+
+	out := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(end < len(f.keys))}
+	for _, k := range f.keys[start:end] {
+		if strings.HasPrefix(k, aws.ToString(in.Prefix)) {
+			out.Contents = append(out.Contents, types.Object{Key: aws.String(k)})
+		}
+	}
+	if end < len(f.keys) {
+		out.NextContinuationToken = aws.String(strconv.Itoa(end))
+	}
+	return out, nil
+}
+
+func (f *fakeS3) DeleteObjects(_ context.Context, in *s3.DeleteObjectsInput, _ ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batchSizes = append(f.batchSizes, len(in.Delete.Objects))
+	out := &s3.DeleteObjectsOutput{}
+	for _, o := range in.Delete.Objects {
+		key := aws.ToString(o.Key)
+		if f.failAlways[key] || f.failOnce[key] {
+			delete(f.failOnce, key)
+			out.Errors = append(out.Errors, types.Error{Key: o.Key, Code: aws.String("InternalError"), Message: aws.String("try again")})
+		}
+	}
+	return out, nil
+}
+
+func newTestRepo(client s3Client) *S3Repo {
+	return &S3Repo{log: logger.New(), client: client, bucket: "test-bucket", directory: "story-images"}
+}
+
+func TestS3Repo_DeleteAll_DeletesMoreThanOneBatch(t *testing.T) {
+	client := &fakeS3{}
+	for i := 0; i < 2500; i++ {
+		client.keys = append(client.keys, fmt.Sprintf("story-images/11/%04d.jpg", i))
+	}
+
+	result, err := newTestRepo(client).DeleteAll(context.Background(), "11")
+
+	assert.NoError(t, err)
+	assert.NoError(t, result.Err())
+	assert.Equal(t, 2500, result.Deleted)
+	sort.Ints(client.batchSizes)
+	assert.Equal(t, []int{500, 1000, 1000}, client.batchSizes)
+}
+
+func TestS3Repo_DeleteAll_RetriesAndReportsFailedKeys(t *testing.T) {
+	client := &fakeS3{
+		keys:       []string{"story-images/11/a.jpg", "story-images/11/b.jpg", "story-images/11/c.jpg", "story-images/110/d.jpg"},
+		failOnce:   map[string]bool{"story-images/11/b.jpg": true},
+		failAlways: map[string]bool{"story-images/11/c.jpg": true},
+	}
+
+	result, err := newTestRepo(client).DeleteAll(context.Background(), "11")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Deleted)
+	assert.Equal(t, []FailedKey{{Key: "story-images/11/c.jpg", Code: "InternalError", Message: "try again"}}, result.Failed)
+	assert.Error(t, result.Err())
+	// a first attempt with all keys, then the two failing keys, then the one that still fails
+	assert.Equal(t, []int{3, 2, 1}, client.batchSizes)
+}
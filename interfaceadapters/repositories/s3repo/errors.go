@@ -0,0 +1,96 @@
+package s3repo
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrCircuitOpen is returned without calling S3 while the circuit breaker is open
+var ErrCircuitOpen = stderrors.New("s3 circuit breaker is open")
+
+// StorageError is returned by the repo when an S3 operation failed
+type StorageError struct {
+	Op  string
+	Key string
+	// Unavailable is set when S3 is throttling, failing server side, can not be reached or the circuit is open,
+	// i.e. the request itself was fine and may succeed later
+	Unavailable bool
+	Err         error
+}
+
+func (e *StorageError) Error() string {
+	return fmt.Sprintf("s3 %s %s: %v", e.Op, e.Key, e.Err)
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// throttleCodes are the S3 error codes that ask the client to slow down
+var throttleCodes = map[string]bool{
+	"SlowDown":             true,
+	"Throttling":           true,
+	"ThrottlingException":  true,
+	"RequestTimeout":       true,
+	"RequestLimitExceeded": true,
+	"ServiceUnavailable":   true,
+	"InternalError":        true,
+}
+
+// isRetryable reports whether the error is a throttling or server side error worth retrying,
+// or S3 could not be reached within the attempt. Errors after ctx is done are not.
+func isRetryable(ctx context.Context, err error) bool {
+	var respErr *awshttp.ResponseError
+	if stderrors.As(err, &respErr) {
+		status := respErr.HTTPStatusCode()
+		if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+			return true
+		}
+	}
+
+	var apiErr smithy.APIError
+	if stderrors.As(err, &apiErr) {
+		return throttleCodes[apiErr.ErrorCode()]
+	}
+
+	// the caller gave up on the call, S3 may well be fine
+	if ctx.Err() != nil {
+		return false
+	}
+	var sendErr *smithyhttp.RequestSendError
+	if stderrors.As(err, &sendErr) || stderrors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return stderrors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns the delay before the given retry, with full jitter over an exponentially growing window
+func backoff(base time.Duration, retry int) time.Duration {
+	window := base << retry
+	if window <= 0 {
+		return 0
+	}
+	return rand.N(window)
+}
+
+// sleep waits for d, returning early with the error of ctx once it is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,65 +2,155 @@ package s3repo
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"path"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 	"github.com/rahul-aut-ind/service-user/internal/awsconfig"
 	"github.com/rahul-aut-ind/service-user/internal/config"
 	"github.com/rahul-aut-ind/service-user/pkg/logger"
-	"golang.org/x/net/context"
 )
 
 type (
 	S3Handler interface {
-		Save(uID string, imageID uuid.UUID, ext string, f *[]byte) (string, error)
-		Delete(uID string, imageID string) error
-		DeleteAll(uID string) error
+		Save(ctx context.Context, uID string, imageID uuid.UUID, ext string, f *[]byte) (string, error)
+		Delete(ctx context.Context, uID string, imageID string) error
+		DeleteAll(ctx context.Context, uID string) (*DeleteResult, error)
+		GetPresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+		Get(ctx context.Context, key string) (io.ReadCloser, error)
+	}
+
+	// s3Client is the part of the S3 api the repo uses
+	s3Client interface {
+		s3.ListObjectsV2APIClient
+		PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+		GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+		DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+		DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+		ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
+		HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
 	}
 
 	S3Repo struct {
-		log       *logger.Logger
-		client    *s3.Client
-		bucket    string
-		directory string
+		log        *logger.Logger
+		client     s3Client
+		httpClient *http.Client
+		presigner  *s3.PresignClient
+		bucket     string
+		directory  string
+		retryDelay time.Duration
+		// timeout bounds every upload attempt, the http client only bounds waiting for the response
+		timeout time.Duration
+		breaker *breaker
 	}
 )
 
+const (
+	// SaveAttempts is how often an upload is tried before it fails
+	SaveAttempts = 3
+	// DefaultRetryDelay is the backoff window of the first retry, doubled on every further one
+	DefaultRetryDelay = 200 * time.Millisecond
+)
+
 // New creates a new instance of S3Repo
 func New(l *logger.Logger, cfg *awsconfig.AWSConfig, env *config.Env) *S3Repo {
+	httpClient := awsconfig.StreamingHTTPClient(env.S3Timeout)
+	client := initializeClient(cfg.Config, env.S3Endpoint, httpClient)
 	return &S3Repo{
-		log:       l,
-		client:    initializeClient(cfg.Config, env.DynamoDBConnectionString),
-		bucket:    env.S3Bucket,
-		directory: env.S3Directory,
+		log:        l,
+		client:     client,
+		httpClient: httpClient,
+		presigner:  s3.NewPresignClient(client),
+		bucket:     env.S3Bucket,
+		directory:  env.S3Directory,
+		retryDelay: DefaultRetryDelay,
+		timeout:    env.S3Timeout,
+		breaker:    newBreaker(BreakerThreshold, BreakerCooldown),
 	}
 }
 
-func initializeClient(cfg *aws.Config, connString string) *s3.Client {
+func initializeClient(cfg *aws.Config, endpoint string, httpClient *http.Client) *s3.Client {
 	return s3.NewFromConfig(*cfg, func(o *s3.Options) {
+		o.HTTPClient = httpClient
 		o.UsePathStyle = true
-		o.BaseEndpoint = aws.String(connString)
+		o.BaseEndpoint = awsconfig.Endpoint(endpoint)
 	})
 }
 
-func (r *S3Repo) Save(uID string, imageID uuid.UUID, ext string, d *[]byte) (string, error) {
+// Save uploads the image, retrying throttling, server side and connection errors with jittered backoff.
+// While S3 keeps failing the circuit breaker opens and uploads fail fast without calling S3.
+func (r *S3Repo) Save(ctx context.Context, uID string, imageID uuid.UUID, ext string, d *[]byte) (string, error) {
 	f := r.getPath(uID, fmt.Sprintf("%s%s", imageID.String(), ext))
 
-	_, err := r.client.PutObject(context.Background(), &s3.PutObjectInput{
-		Bucket: &r.bucket,
-		Key:    &f,
-		Body:   bytes.NewReader(*d),
-	})
-	if err != nil {
-		r.log.Fatalf("S3 PutObject err %+v", err)
-		return f, err
+	ok, trial := r.breaker.allow()
+	if !ok {
+		return f, &StorageError{Op: "PutObject", Key: f, Unavailable: true, Err: ErrCircuitOpen}
+	}
+	defer r.breaker.release(trial)
+
+	var err error
+	for attempt := 1; attempt <= SaveAttempts; attempt++ {
+		attemptCtx, cancel := r.attemptContext(ctx)
+		_, err = r.client.PutObject(attemptCtx, &s3.PutObjectInput{
+			Bucket: &r.bucket,
+			Key:    &f,
+			Body:   bytes.NewReader(*d),
+		}, func(o *s3.Options) {
+			// retries are done here, so they share the backoff and the breaker
+			o.RetryMaxAttempts = 1
+		})
+		cancel()
+		if err == nil {
+			r.breaker.success()
+			return f, nil
+		}
+		if !isRetryable(ctx, err) {
+			break
+		}
+		if attempt < SaveAttempts {
+			r.log.For(ctx).Warnf("S3 PutObject of %s failed, attempt %d :: %v", f, attempt, err)
+			if sleepErr := sleep(ctx, backoff(r.retryDelay, attempt-1)); sleepErr != nil {
+				err = sleepErr
+				break
+			}
+		}
+	}
+
+	r.log.For(ctx).Errorf("S3 PutObject of %s failed :: %v", f, err)
+	unavailable := isRetryable(ctx, err)
+	if unavailable {
+		// only an unhealthy S3 counts towards opening the circuit, not a rejected request
+		r.breaker.failure()
+	}
+	return f, &StorageError{Op: "PutObject", Key: f, Unavailable: unavailable, Err: err}
+}
+
+// attemptContext bounds a single attempt of a call by the timeout, if one is configured
+func (r *S3Repo) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, r.timeout)
+}
 
-	return f, nil
+// Close drops the idle connections to S3
+func (r *S3Repo) Close() error {
+	if r.httpClient != nil {
+		r.httpClient.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Ping checks that the bucket exists and is accessible
+func (r *S3Repo) Ping(ctx context.Context) error {
+	_, err := r.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &r.bucket})
+	return err
 }
 
 // nolint:unused // needed for local debug sometimes, not part of functionality
@@ -78,51 +168,41 @@ func (r *S3Repo) getPath(uID, imageID string) string {
 	return path.Join(r.directory, uID, imageID)
 }
 
-func (r *S3Repo) Delete(uID, imageID string) error {
-	f := r.getPath(uID, imageID)
-
-	_, err := r.client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+// Get streams the object with the given key, the caller must close the returned reader
+func (r *S3Repo) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := r.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: &r.bucket,
-		Key:    &f,
+		Key:    &key,
 	})
+	if err != nil {
+		r.log.For(ctx).Errorf("error getting object %s in bucket %s: %v", key, r.bucket, err)
+		return nil, err
+	}
 
-	return err
+	return out.Body, nil
 }
 
-func (r *S3Repo) DeleteAll(uID string) error {
-	prefix := r.getPath(uID, "")
-
-	listOutput, err := r.client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+// GetPresignedURL returns a url to read the object with the given key, valid for the expiry duration
+func (r *S3Repo) GetPresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	req, err := r.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
 		Bucket: &r.bucket,
-		Prefix: &prefix,
-	})
+		Key:    &key,
+	}, s3.WithPresignExpires(expiry))
 	if err != nil {
-		r.log.Errorf("error listing objects in bucket %s with prefix %s: %v", r.bucket, prefix, err)
-		return err
+		r.log.For(ctx).Errorf("error presigning object %s in bucket %s: %v", key, r.bucket, err)
+		return "", err
 	}
 
-	// Prepare the list of objects to delete
-	var objectsToDelete = make([]types.ObjectIdentifier, len(listOutput.Contents))
-	for i, object := range listOutput.Contents {
-		objectsToDelete[i] = types.ObjectIdentifier{
-			Key: object.Key,
-		}
-	}
+	return req.URL, nil
+}
 
-	if len(objectsToDelete) == 0 {
-		return nil
-	}
+func (r *S3Repo) Delete(ctx context.Context, uID, imageID string) error {
+	f := r.getPath(uID, imageID)
 
-	_, err = r.client.DeleteObjects(context.Background(), &s3.DeleteObjectsInput{
+	_, err := r.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: &r.bucket,
-		Delete: &types.Delete{
-			Objects: objectsToDelete,
-		},
+		Key:    &f,
 	})
 
-	if err != nil {
-		r.log.Errorf("error deleting objects in bucket %s with prefix %s: %v", r.bucket, prefix, err)
-	}
-
 	return err
 }
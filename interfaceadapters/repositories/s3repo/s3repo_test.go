@@ -0,0 +1,213 @@
+package s3repo
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	// putS3 answers PutObject with the queued errors, then succeeds
+	putS3 struct {
+		s3Client
+		errs  []error
+		calls int
+	}
+
+	// hangingS3 never answers PutObject, the call ends with its context
+	hangingS3 struct {
+		s3Client
+		calls int
+	}
+)
+
+func (h *hangingS3) PutObject(ctx context.Context, _ *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	h.calls++
+	<-ctx.Done()
+	return nil, &smithyhttp.RequestSendError{Err: ctx.Err()}
+}
+
+func (p *putS3) PutObject(_ context.Context, _ *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	p.calls++
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return nil, err
+	}
+	return &s3.PutObjectOutput{}, nil
+}
+
+func newSaveRepo(client s3Client) *S3Repo {
+	r := newTestRepo(client)
+	r.breaker = newBreaker(BreakerThreshold, BreakerCooldown)
+	return r
+}
+
+var (
+	slowDown     = &smithy.GenericAPIError{Code: "SlowDown", Message: "reduce your request rate"}
+	accessDenied = &smithy.GenericAPIError{Code: "AccessDenied", Message: "access denied"}
+	image        = []byte("image")
+)
+
+func TestS3Repo_Save_RetriesThrottling(t *testing.T) {
+	client := &putS3{errs: []error{slowDown, slowDown}}
+
+	path, err := newSaveRepo(client).Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+
+	assert.NoError(t, err)
+	assert.Contains(t, path, "story-images/11/")
+	assert.Equal(t, 3, client.calls)
+}
+
+func TestS3Repo_Save_DoesNotRetryRejectedRequest(t *testing.T) {
+	client := &putS3{errs: []error{accessDenied}}
+
+	_, err := newSaveRepo(client).Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+
+	var storageErr *StorageError
+	assert.True(t, stderrors.As(err, &storageErr))
+	assert.False(t, storageErr.Unavailable)
+	assert.ErrorIs(t, err, accessDenied)
+	assert.Equal(t, 1, client.calls)
+}
+
+func TestS3Repo_Save_StopsRetryingWhenCancelled(t *testing.T) {
+	client := &putS3{errs: []error{slowDown, slowDown}}
+	repo := newSaveRepo(client)
+	repo.retryDelay = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.Save(ctx, "11", uuid.New(), ".jpg", &image)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, client.calls)
+}
+
+func TestS3Repo_Save_OpensCircuitWhileS3IsDown(t *testing.T) {
+	client := &putS3{}
+	for i := 0; i < BreakerThreshold*SaveAttempts; i++ {
+		client.errs = append(client.errs, slowDown)
+	}
+	repo := newSaveRepo(client)
+	now := time.Now()
+	repo.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < BreakerThreshold; i++ {
+		_, err := repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+		assert.Error(t, err)
+	}
+	calls := client.calls
+
+	_, err := repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+	var storageErr *StorageError
+	assert.True(t, stderrors.As(err, &storageErr))
+	assert.True(t, storageErr.Unavailable)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, calls, client.calls)
+
+	// after the cooldown a trial upload goes through and closes the circuit
+	now = now.Add(BreakerCooldown)
+	_, err = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+	assert.NoError(t, err)
+	_, err = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+	assert.NoError(t, err)
+}
+
+func TestS3Repo_Save_RetriesUnreachableS3AndOpensCircuit(t *testing.T) {
+	refused := &smithyhttp.RequestSendError{Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "request not sent", err: refused},
+		{name: "attempt timed out", err: &smithyhttp.RequestSendError{Err: context.DeadlineExceeded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &putS3{}
+			for i := 0; i < BreakerThreshold*SaveAttempts; i++ {
+				client.errs = append(client.errs, tt.err)
+			}
+			repo := newSaveRepo(client)
+
+			_, err := repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+			var storageErr *StorageError
+			assert.True(t, stderrors.As(err, &storageErr))
+			assert.True(t, storageErr.Unavailable)
+			assert.Equal(t, SaveAttempts, client.calls)
+
+			for i := 1; i < BreakerThreshold; i++ {
+				_, _ = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+			}
+			_, err = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+			assert.ErrorIs(t, err, ErrCircuitOpen)
+		})
+	}
+}
+
+func TestS3Repo_Save_RetriesAttemptsTimingOut(t *testing.T) {
+	client := &hangingS3{}
+	repo := newSaveRepo(client)
+	repo.timeout = 10 * time.Millisecond
+
+	_, err := repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+
+	var storageErr *StorageError
+	assert.True(t, stderrors.As(err, &storageErr))
+	assert.True(t, storageErr.Unavailable)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, SaveAttempts, client.calls)
+}
+
+func TestS3Repo_Save_UnsettledTrialReopensCircuit(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		errs []error
+	}{
+		{name: "rejected", ctx: context.Background(), errs: []error{accessDenied}},
+		{name: "cancelled", ctx: cancelled, errs: []error{slowDown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &putS3{}
+			for i := 0; i < BreakerThreshold*SaveAttempts; i++ {
+				client.errs = append(client.errs, slowDown)
+			}
+			repo := newSaveRepo(client)
+			now := time.Now()
+			repo.breaker.now = func() time.Time { return now }
+			for i := 0; i < BreakerThreshold; i++ {
+				_, _ = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+			}
+			// the cancelled trial stops in its backoff
+			repo.retryDelay = time.Minute
+
+			// the trial ends neither in success nor in an S3 failure
+			now = now.Add(BreakerCooldown)
+			client.errs = tt.errs
+			_, err := repo.Save(tt.ctx, "11", uuid.New(), ".jpg", &image)
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, ErrCircuitOpen)
+
+			_, err = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+			assert.ErrorIs(t, err, ErrCircuitOpen, "open for another cooldown")
+
+			now = now.Add(BreakerCooldown)
+			_, err = repo.Save(context.Background(), "11", uuid.New(), ".jpg", &image)
+			assert.NoError(t, err, "a new trial closes the circuit")
+		})
+	}
+}
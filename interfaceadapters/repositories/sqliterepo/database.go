@@ -0,0 +1,51 @@
+package sqliterepo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// pragmas make concurrent requests wait for the write lock instead of failing with SQLITE_BUSY
+const pragmas = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+// New opens the SQLite database file of the connection string, creating it if needed.
+// The driver is pure Go, so the binary builds without CGO.
+func New(l *logger.Logger, env *config.Env) *mysqlrepo.GormClient {
+	client := mysqlrepo.Connect(Dialector(env), env.AutoMigrate, l)
+
+	// SQLite has a single writer, more connections only contend for the lock
+	sqlDB, _ := client.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	return mysqlrepo.NewGormClient(client, env.DBTimeout, l)
+}
+
+// Dialector opens the SQLite database file of the connection string, creating its directory
+func Dialector(env *config.Env) gorm.Dialector {
+	dsn := config.TrimDBScheme(env.DBConnectionString)
+
+	file, _, _ := strings.Cut(dsn, "?")
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		panic(fmt.Sprintf("failed to create database directory :: %v", err))
+	}
+
+	return sqlite.Open(withPragmas(dsn))
+}
+
+func withPragmas(dsn string) string {
+	if strings.Contains(dsn, "_pragma=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + pragmas
+	}
+	return dsn + "?" + pragmas
+}
@@ -0,0 +1,26 @@
+package sqliterepo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/mysqlrepo/mysqlrepotest"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepoTestSuite struct {
+	mysqlrepotest.UserRepoSuite
+}
+
+func (s *RepoTestSuite) SetupSuite() {
+	dsn := "sqlite://" + filepath.Join(s.T().TempDir(), "data", "users.db")
+	repo := New(logger.New(), &config.Env{DBConnectionString: dsn, AutoMigrate: true})
+	s.NewRepo = func() mysqlrepo.DataHandler { return repo }
+}
+
+func TestRepoSuite(t *testing.T) {
+	suite.Run(t, new(RepoTestSuite))
+}
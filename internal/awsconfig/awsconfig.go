@@ -2,42 +2,107 @@ package awsconfig
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	environ "github.com/rahul-aut-ind/service-user/internal/config"
 )
 
-type AWSConfig struct {
-	Config *aws.Config
-}
+type (
+	AWSConfig struct {
+		Config *aws.Config
+		// credentials are the configured access keys, nil if the credentials come from the instance
+		credentials *rotatingCredentials
+	}
+
+	// rotatingCredentials hands out the configured access keys, the clients cache them until Rotate
+	// invalidates the cache
+	rotatingCredentials struct {
+		value atomic.Pointer[aws.Credentials]
+		cache *aws.CredentialsCache
+	}
+)
 
-// nolint // already aware of deprecated warning
+// NewAWSConfig loads the config shared by the AWS clients, the endpoint of each service is set by its client,
+// see Endpoint. The configured access keys are used if there are any, otherwise the default credential chain,
+// like the role of the instance, provides them.
 func NewAWSConfig(env *environ.Env) *AWSConfig {
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(env.DefaultAWSRegion),
-	)
-	if env.Environment == environ.LocalEnvironment {
-		cfg, err = config.LoadDefaultConfig(context.TODO(),
-			config.WithRegion(env.DefaultAWSRegion),
-			config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
-				Value: aws.Credentials{
-					AccessKeyID:     env.AwsAccessKey,
-					SecretAccessKey: env.AwsSecretAccessKey,
-				}}),
-			config.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(
-				func(service, region string, options ...interface{}) (aws.Endpoint, error) {
-					return aws.Endpoint{
-						URL:           env.DynamoDBConnectionString,
-						SigningRegion: env.DefaultAWSRegion,
-					}, nil
-				})),
-		)
+	opts := []func(*config.LoadOptions) error{config.WithRegion(env.DefaultAWSRegion)}
+
+	var creds *rotatingCredentials
+	if env.AwsAccessKey != "" {
+		creds = &rotatingCredentials{}
+		creds.set(env)
+		creds.cache = aws.NewCredentialsCache(creds)
+		opts = append(opts, config.WithCredentialsProvider(creds.cache))
 	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(), opts...)
 	if err != nil {
 		log.Fatalf("unable to load AWS config, %v", err)
 	}
-	return &AWSConfig{Config: &cfg}
+	return &AWSConfig{Config: &cfg, credentials: creds}
+}
+
+// Rotate switches the clients to the access keys of env, with their next call
+func (c *AWSConfig) Rotate(_ context.Context, env *environ.Env) error {
+	if c.credentials == nil {
+		return errors.New("the AWS credentials come from the default chain, access keys can not be set without a restart")
+	}
+	c.credentials.set(env)
+	c.credentials.cache.Invalidate()
+	return nil
+}
+
+func (r *rotatingCredentials) set(env *environ.Env) {
+	r.value.Store(&aws.Credentials{
+		AccessKeyID:     env.AwsAccessKey,
+		SecretAccessKey: env.AwsSecretAccessKey,
+		SessionToken:    env.AwsSessionToken,
+		Source:          "service-user config",
+	})
+}
+
+// Retrieve returns the current access keys
+func (r *rotatingCredentials) Retrieve(context.Context) (aws.Credentials, error) {
+	return *r.value.Load(), nil
+}
+
+// HTTPClient returns the http client of an AWS service client, every attempt of a call is bounded by timeout.
+// It uses the transport settings of the SDK, keeping the transport to close its connections on shutdown.
+func HTTPClient(timeout time.Duration) *http.Client {
+	client := StreamingHTTPClient(timeout)
+	client.Timeout = timeout
+	return client
+}
+
+// StreamingHTTPClient returns the http client of an AWS service client streaming large bodies. Connecting and
+// waiting for the response headers are bounded by timeout, reading the body is not, so a large object is not
+// cut off midway. Calls sending large bodies bound their attempts by their context.
+func StreamingHTTPClient(timeout time.Duration) *http.Client {
+	transport := awshttp.NewBuildableClient().
+		WithDialerOptions(func(d *net.Dialer) {
+			d.Timeout = timeout
+		}).
+		WithTransportOptions(func(t *http.Transport) {
+			t.TLSHandshakeTimeout = timeout
+			t.ResponseHeaderTimeout = timeout
+		}).
+		GetTransport()
+	return &http.Client{Transport: transport}
+}
+
+// Endpoint returns the client option overriding the endpoint of the region with the url, if one is configured
+func Endpoint(url string) *string {
+	if url == "" {
+		return nil
+	}
+	return aws.String(url)
 }
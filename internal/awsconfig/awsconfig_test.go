@@ -0,0 +1,67 @@
+package awsconfig
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	environ "github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSConfig_RotatesAccessKeys(t *testing.T) {
+	cfg := NewAWSConfig(&environ.Env{DefaultAWSRegion: "eu-central-1", AwsAccessKey: "key-1", AwsSecretAccessKey: "secret-1"})
+	ctx := context.Background()
+
+	creds, err := cfg.Config.Credentials.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", creds.AccessKeyID)
+
+	require.NoError(t, cfg.Rotate(ctx, &environ.Env{AwsAccessKey: "key-2", AwsSecretAccessKey: "secret-2"}))
+
+	creds, err = cfg.Config.Credentials.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", creds.AccessKeyID)
+	assert.Equal(t, "secret-2", creds.SecretAccessKey)
+}
+
+func TestAWSConfig_RotateNeedsAccessKeys(t *testing.T) {
+	cfg := NewAWSConfig(&environ.Env{DefaultAWSRegion: "eu-central-1"})
+
+	assert.Error(t, cfg.Rotate(context.Background(), &environ.Env{AwsAccessKey: "key"}))
+}
+
+func TestStreamingHTTPClient_DoesNotCutOffSlowBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := StreamingHTTPClient(150 * time.Millisecond).Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "chunkchunkchunk", string(body))
+}
+
+func TestStreamingHTTPClient_BoundsResponseHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	_, err := StreamingHTTPClient(100 * time.Millisecond).Get(srv.URL)
+	assert.Error(t, err)
+}
@@ -1,83 +1,421 @@
 package config
 
 import (
-	"fmt"
-	"log"
-	"os"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/rahul-aut-ind/service-user/internal/secrets"
 )
 
 type (
+	// Mode selects how the service boots, see ModeService and ModeStandalone
+	Mode string
+
+	// Pool sizes a database connection pool
+	Pool struct {
+		MaxOpenConns int `yaml:"max_open_conns" env:"Max_Open_Conns"`
+		MaxIdleConns int `yaml:"max_idle_conns" env:"Max_Idle_Conns"`
+	}
+
+	// RateLimit allows Requests per sliding Window
+	RateLimit struct {
+		Requests int           `yaml:"requests" env:"Requests"`
+		Window   time.Duration `yaml:"window" env:"Window"`
+	}
+
+	// Env is the config of the service, see Load for where it is read from. Every setting has a yaml key,
+	// one or more env variables, the first set one winning, and a command line flag named like the yaml key.
+	// Settings tagged secret may hold a reference to the secret, like file:///run/secrets/db, resolved by Load
+	// and read again by Refresh. Print masks them, dsn only masks the password of a connection string.
 	Env struct {
+		// Mode the service was started in
+		Mode Mode `yaml:"-"`
 		// Environment the development environment
-		Environment string
-		// DBConnectionString the connections string
-		DBConnectionString string
+		Environment string `yaml:"environment" env:"Environment"`
+		// DBConnectionString the connections string, its scheme selects the database, see DBDriver
+		DBConnectionString string `yaml:"db_connection_string" env:"MysqlDB_Connection_String" secret:"dsn"`
+		// DBReplicaConnectionStrings are the read replicas of the user database, same driver as the primary
+		DBReplicaConnectionStrings []string `yaml:"db_replica_connection_strings" env:"MysqlDB_Replica_Connection_Strings" secret:"dsn"`
+		// DBPrimaryPool sizes the connection pool of the primary
+		DBPrimaryPool Pool `yaml:"db_primary_pool" env:"DB_Primary_"`
+		// DBReplicaPool sizes the connection pool of every replica
+		DBReplicaPool Pool `yaml:"db_replica_pool" env:"DB_Replica_"`
+		// DBReplicaMaxLag is how far a replica may fall behind the primary before reads skip it
+		DBReplicaMaxLag time.Duration `yaml:"db_replica_max_lag" env:"DB_Replica_Max_Lag"`
+		// DBReplicaCheckInterval is how often the replication lag of the replicas is checked
+		DBReplicaCheckInterval time.Duration `yaml:"db_replica_check_interval" env:"DB_Replica_Check_Interval"`
+		// DBTimeout bounds every query of the user database
+		DBTimeout time.Duration `yaml:"db_timeout" env:"DB_Timeout"`
+		// AutoMigrate applies pending migrations of the user database on boot, off in production
+		// where `service-user migrate up` is run before rolling out
+		AutoMigrate bool `yaml:"auto_migrate" env:"Auto_Migrate"`
 		// ServerHost the host that the server will start on
-		ServerHost string
+		ServerHost string `yaml:"server_host" env:"Server_Host"`
 		// ServerPort the port that server will start on
-		ServerPort string
+		ServerPort string `yaml:"server_port" env:"Server_Port"`
+		// ServerReadTimeout bounds reading a request including its body, uploads included
+		ServerReadTimeout time.Duration `yaml:"server_read_timeout" env:"Server_Read_Timeout"`
+		// ServerWriteTimeout bounds writing a response, archive downloads extend it for themselves
+		ServerWriteTimeout time.Duration `yaml:"server_write_timeout" env:"Server_Write_Timeout"`
+		// ServerIdleTimeout is how long a keep-alive connection waits for the next request
+		ServerIdleTimeout time.Duration `yaml:"server_idle_timeout" env:"Server_Idle_Timeout"`
+		// ShutdownTimeout is how long in-flight requests may take to finish once the service is stopped
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"Shutdown_Timeout"`
 		// RedisAddress the server and port where redis will run
-		RedisAddress string
+		RedisAddress string `yaml:"redis_address" env:"Redis_Address"`
+		// RedisTimeout bounds every dial, read and write of redis
+		RedisTimeout time.Duration `yaml:"redis_timeout" env:"Redis_Timeout"`
 		// DefaultAWSRegion default AWS region
-		DefaultAWSRegion string
-		// DynamoDBConnectionString the connections string
-		DynamoDBConnectionString string
-		// DynamoDBTable is the table name in dynamoDB
-		DynamoDBTable string
-		// AwsAccessKey is the aws access key
-		AwsAccessKey string
+		DefaultAWSRegion string `yaml:"aws_region" env:"AWS_REGION"`
+		// AwsAccessKey is the aws access key, without it the credentials are taken from the environment
+		// of the instance, like its role
+		AwsAccessKey string `yaml:"aws_access_key_id" env:"AWS_ACCESS_KEY_ID" secret:"true"`
 		// AwsSecretAccessKey is the Aws SecretAccess Key
-		AwsSecretAccessKey string
+		AwsSecretAccessKey string `yaml:"aws_secret_access_key" env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+		// AwsSessionToken is the session token of temporary access keys
+		AwsSessionToken string `yaml:"aws_session_token" env:"AWS_SESSION_TOKEN" secret:"true"`
+		// DynamoDBEndpoint overrides the dynamoDB endpoint of the region, e.g. for localstack.
+		// DynamoDB_Connection_String is its former name
+		DynamoDBEndpoint string `yaml:"dynamodb_endpoint" env:"DynamoDB_Endpoint,DynamoDB_Connection_String"`
+		// DynamoDBTimeout bounds every attempt of a dynamoDB call
+		DynamoDBTimeout time.Duration `yaml:"dynamodb_timeout" env:"DynamoDB_Timeout"`
+		// DynamoDBTable is the table name in dynamoDB
+		DynamoDBTable string `yaml:"dynamodb_table" env:"DynamoDB_Table"`
+		// DynamoDBAlbumTable is the table name of user albums in dynamoDB
+		DynamoDBAlbumTable string `yaml:"dynamodb_album_table" env:"DynamoDB_Album_Table"`
+		// DynamoDBShareTable is the table name of image shares in dynamoDB
+		DynamoDBShareTable string `yaml:"dynamodb_share_table" env:"DynamoDB_Share_Table"`
+		// S3Endpoint overrides the S3 endpoint of the region, e.g. for localstack
+		S3Endpoint string `yaml:"s3_endpoint" env:"S3_Endpoint"`
 		// S3Bucket is the S3 bucket name
-		S3Bucket string
+		S3Bucket string `yaml:"s3_bucket" env:"S3Bucket"`
 		// S3Directory is the S3 directory in the bucket
-		S3Directory string
+		S3Directory string `yaml:"s3_directory" env:"S3Directory"`
+		// S3Timeout bounds connecting to S3 and waiting for its answers, and every attempt of an upload.
+		// Downloads are not cut off while their body streams
+		S3Timeout time.Duration `yaml:"s3_timeout" env:"S3_Timeout"`
+		// Cache selects the user cache, CacheRedis or CacheMemory
+		Cache string `yaml:"cache" env:"Cache"`
+		// ImageDB selects where image records are kept, ImageDBDynamo or ImageDBMemory
+		ImageDB string `yaml:"image_db" env:"Image_DB"`
+		// ImageStorage selects where image files are kept, ImageStorageS3 or ImageStorageFS
+		ImageStorage string `yaml:"image_storage" env:"Image_Storage"`
+		// LocalStorageDir is the directory holding the image files with ImageStorageFS
+		LocalStorageDir string `yaml:"local_storage_dir" env:"Local_Storage_Dir"`
+		// LocalStorageSecret is the key signing the local image urls with ImageStorageFS
+		LocalStorageSecret string `yaml:"local_storage_secret" env:"Local_Storage_Secret" secret:"true"`
+		// HealthCheckTimeout bounds each dependency check of the readiness endpoint
+		HealthCheckTimeout time.Duration `yaml:"health_check_timeout" env:"Health_Check_Timeout"`
+		// LogLevel is the minimum level logged on boot, debug, info, warn or error. It can be changed at runtime
+		// through the admin endpoint
+		LogLevel string `yaml:"log_level" env:"Log_Level"`
+		// LogFormat is LogFormatJSON or LogFormatConsole
+		LogFormat string `yaml:"log_format" env:"Log_Format"`
+		// LogSampling thins out lines repeating the same message many times a second
+		LogSampling bool `yaml:"log_sampling" env:"Log_Sampling"`
+		// AdminToken is the credential of the admin endpoints, they are closed while it is empty
+		AdminToken string `yaml:"admin_token" env:"Admin_Token" secret:"true"`
+		// TracingExporter selects where spans are sent, TracingExporterNone, TracingExporterOTLP or TracingExporterStdout
+		TracingExporter string `yaml:"tracing_exporter" env:"Tracing_Exporter"`
+		// OTLPEndpoint is the host and port of the collector receiving spans over OTLP/HTTP
+		OTLPEndpoint string `yaml:"otlp_endpoint" env:"OTLP_Endpoint"`
+		// OTLPInsecure sends spans to the collector over plain http
+		OTLPInsecure bool `yaml:"otlp_insecure" env:"OTLP_Insecure"`
+		// TracingSampleRatio is the share of traces started by the service that are recorded,
+		// incoming requests keep the sampling decision of their caller
+		TracingSampleRatio float64 `yaml:"tracing_sample_ratio" env:"Tracing_Sample_Ratio"`
+		// ImageQuotaBytes is the max total size of images a user may store
+		ImageQuotaBytes int64 `yaml:"image_quota_bytes" env:"Image_Quota_Bytes"`
+		// ImageQuotaCount is the max number of images a user may store
+		ImageQuotaCount int64 `yaml:"image_quota_count" env:"Image_Quota_Count"`
+		// MaxUploadBytes is the max size of a request body read into memory before it is handled,
+		// like an upload sent with an Idempotency-Key
+		MaxUploadBytes int64 `yaml:"max_upload_bytes" env:"Max_Upload_Bytes"`
+		// RateLimitEnabled limits the requests of every user, or client ip without a user, per route group
+		RateLimitEnabled bool `yaml:"rate_limit_enabled" env:"Rate_Limit_Enabled"`
+		// RateLimitUsers limits the requests to the users api
+		RateLimitUsers RateLimit `yaml:"rate_limit_users" env:"Rate_Limit_Users_"`
+		// RateLimitImages limits the requests to the user images api, uploads included
+		RateLimitImages RateLimit `yaml:"rate_limit_images" env:"Rate_Limit_Images_"`
+		// RateLimitUploads limits the image uploads on top of RateLimitImages
+		RateLimitUploads RateLimit `yaml:"rate_limit_uploads" env:"Rate_Limit_Uploads_"`
+		// RateLimitPublic limits the requests to the share links and signed local urls, by client ip
+		RateLimitPublic RateLimit `yaml:"rate_limit_public" env:"Rate_Limit_Public_"`
+		// CacheTTL is how long a cached user is fresh, spread by CacheTTLJitter
+		CacheTTL time.Duration `yaml:"cache_ttl" env:"Cache_TTL"`
+		// CacheNotFoundTTL is how long an unknown user id is cached as not found, 0 does not cache it
+		CacheNotFoundTTL time.Duration `yaml:"cache_not_found_ttl" env:"Cache_Not_Found_TTL"`
+		// CacheStaleTTL is how long a cached user is served after CacheTTL while it is refreshed in the background
+		CacheStaleTTL time.Duration `yaml:"cache_stale_ttl" env:"Cache_Stale_TTL"`
+		// CacheTTLJitter is the share of the TTLs by which they are randomly made shorter or longer,
+		// so users cached together do not expire together
+		CacheTTLJitter float64 `yaml:"cache_ttl_jitter" env:"Cache_TTL_Jitter"`
+		// IdempotencyKeyTTL is how long the response to a request with an Idempotency-Key is replayed
+		IdempotencyKeyTTL time.Duration `yaml:"idempotency_key_ttl" env:"Idempotency_Key_TTL"`
+		// IdempotencyLockTTL is how long a request with an Idempotency-Key holds it while running,
+		// it is to outlast the slowest request, e.g. a large upload
+		IdempotencyLockTTL time.Duration `yaml:"idempotency_lock_ttl" env:"Idempotency_Lock_TTL"`
+		// SecretsRefreshInterval is how often the secret references are read again to pick up rotated secrets,
+		// 0 reads them on boot only
+		SecretsRefreshInterval time.Duration `yaml:"secrets_refresh_interval" env:"Secrets_Refresh_Interval"`
+
+		// resolver resolves the secret references, set by Load
+		resolver *secrets.Resolver
+		// refs are the values of the secret settings as configured, before their references were resolved
+		refs map[string]interface{}
 	}
 )
 
+const (
+	// ModeService boots with the external databases, cache and storage of the config
+	ModeService Mode = "service"
+	// ModeStandalone boots with SQLite, an in-memory cache and local image storage, needing nothing but the binary
+	ModeStandalone Mode = "standalone"
+)
+
 const (
 	// LocalEnvironment is the local dev environment
 	LocalEnvironment = "development"
+	// ProductionEnvironment is the production environment
+	ProductionEnvironment = "production"
 	// HeaderUserID name of the header that holds the user id
 	HeaderUserID = "x-user-id"
 	// HeaderIDToken name of the header that holds the id token
 	HeaderIDToken = "x-id-token"
+	// HeaderRequestID name of the header that holds the id of a request, taken from the caller or generated
+	HeaderRequestID = "x-request-id"
+	// HeaderAdminToken name of the header that holds the admin token
+	HeaderAdminToken = "x-admin-token"
+	// HeaderSharePassword name of the header that holds the password of a public share link
+	HeaderSharePassword = "x-share-password"
+	// HeaderRateLimitPolicy name of the header that holds the rate limit of a route group, like 300;w=60
+	HeaderRateLimitPolicy = "RateLimit-Policy"
+	// HeaderRateLimitLimit name of the header that holds the requests allowed per window
+	HeaderRateLimitLimit = "RateLimit-Limit"
+	// HeaderRateLimitRemaining name of the header that holds the requests left in the window
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	// HeaderRateLimitReset name of the header that holds the seconds until the window ends
+	HeaderRateLimitReset = "RateLimit-Reset"
+	// HeaderRetryAfter name of the header that holds the seconds to wait before retrying
+	HeaderRetryAfter = "Retry-After"
+	// HeaderIdempotencyKey name of the header that holds the key a client retries a request with
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed name of the header that marks a response replayed for its Idempotency-Key
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
 	// HeaderContentType name of the header that holds the content type
 	HeaderContentType = "content-type"
+	// DefaultServerPort is the port the server listens on if none is configured
+	DefaultServerPort = "8080"
+	// DefaultImageQuotaBytes is the per user image storage quota if none is configured
+	DefaultImageQuotaBytes = 1 << 30
+	// DefaultImageQuotaCount is the per user image count quota if none is configured
+	DefaultImageQuotaCount = 1000
+	// DefaultMaxUploadBytes bounds the request bodies read into memory if not configured
+	DefaultMaxUploadBytes = 32 << 20
+	// DBDriverMySQL is the user database for connection strings without or with the mysql:// scheme
+	DBDriverMySQL = "mysql"
+	// DBDriverPostgres is the user database for connection strings with the postgres:// or postgresql:// scheme
+	DBDriverPostgres = "postgres"
+	// DBDriverSQLite is the user database for connection strings with the sqlite:// scheme
+	DBDriverSQLite = "sqlite"
+	// DefaultStandaloneDB is the user database of ModeStandalone if no SQLite database is configured
+	DefaultStandaloneDB = "sqlite://./data/service-user.db"
+	// DefaultDBPoolSize is the max open and idle connections of a user database pool if none is configured
+	DefaultDBPoolSize = 5
+	// DefaultDBReplicaMaxLag is the replication lag a replica may have if none is configured
+	DefaultDBReplicaMaxLag = 5 * time.Second
+	// DefaultDBReplicaCheckInterval is how often replicas are checked if not configured
+	DefaultDBReplicaCheckInterval = 10 * time.Second
+	// DefaultServerReadTimeout bounds reading requests if not configured
+	DefaultServerReadTimeout = time.Minute
+	// DefaultServerWriteTimeout bounds writing responses if not configured
+	DefaultServerWriteTimeout = 2 * time.Minute
+	// DefaultServerIdleTimeout bounds idle keep-alive connections if not configured
+	DefaultServerIdleTimeout = 2 * time.Minute
+	// DefaultShutdownTimeout is the drain deadline on shutdown if not configured
+	DefaultShutdownTimeout = 30 * time.Second
+	// DefaultDBTimeout bounds user database queries if not configured
+	DefaultDBTimeout = 5 * time.Second
+	// DefaultRedisTimeout bounds redis calls if not configured
+	DefaultRedisTimeout = time.Second
+	// DefaultDynamoDBTimeout bounds dynamoDB calls if not configured
+	DefaultDynamoDBTimeout = 5 * time.Second
+	// DefaultS3Timeout bounds S3 calls if not configured
+	DefaultS3Timeout = 30 * time.Second
+	// DefaultHealthCheckTimeout bounds readiness checks if not configured
+	DefaultHealthCheckTimeout = 2 * time.Second
+	// LogFormatJSON logs a json object per line
+	LogFormatJSON = "json"
+	// LogFormatConsole logs human readable lines
+	LogFormatConsole = "console"
+	// DefaultLogLevel is the log level if none is configured
+	DefaultLogLevel = "info"
+	// TracingExporterNone records no spans, trace ids are still passed on to the logs
+	TracingExporterNone = "none"
+	// TracingExporterOTLP sends spans to an OTLP collector
+	TracingExporterOTLP = "otlp"
+	// TracingExporterStdout writes spans to stdout, for local use
+	TracingExporterStdout = "stdout"
+	// DefaultOTLPEndpoint is the collector address if none is configured
+	DefaultOTLPEndpoint = "localhost:4318"
+	// DefaultTracingSampleRatio records every trace if not configured
+	DefaultTracingSampleRatio = 1.0
+	// DefaultSecretsRefreshInterval is how often secret references are read again if not configured
+	DefaultSecretsRefreshInterval = time.Minute
+	// DefaultRateLimitRequests is the requests a user or client ip may send to a route group per window if not configured
+	DefaultRateLimitRequests = 300
+	// DefaultRateLimitUploads is the images a user may upload per window if not configured
+	DefaultRateLimitUploads = 30
+	// DefaultRateLimitWindow is the sliding window of the rate limits if not configured
+	DefaultRateLimitWindow = time.Minute
+	// RateLimitGroupUsers names the rate limit of the users api
+	RateLimitGroupUsers = "users"
+	// RateLimitGroupImages names the rate limit of the user images api
+	RateLimitGroupImages = "images"
+	// RateLimitGroupUploads names the rate limit of the image uploads
+	RateLimitGroupUploads = "uploads"
+	// RateLimitGroupPublic names the rate limit of the public share links and local files
+	RateLimitGroupPublic = "public"
+	// DefaultCacheTTL is how long a cached user is fresh if not configured
+	DefaultCacheTTL = 30 * time.Second
+	// DefaultCacheNotFoundTTL is how long an unknown user id is cached if not configured
+	DefaultCacheNotFoundTTL = 5 * time.Second
+	// DefaultCacheStaleTTL is how long a stale user is served while refreshed if not configured
+	DefaultCacheStaleTTL = 30 * time.Second
+	// DefaultCacheTTLJitter spreads the TTLs by 10% if not configured
+	DefaultCacheTTLJitter = 0.1
+	// DefaultIdempotencyKeyTTL is how long responses are replayed for their Idempotency-Key if not configured
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	// DefaultIdempotencyLockTTL is how long a running request holds its Idempotency-Key if not configured
+	DefaultIdempotencyLockTTL = 5 * time.Minute
+	// HealthzPath is the liveness endpoint, answering as long as the process runs
+	HealthzPath = "/healthz"
+	// ReadyzPath is the readiness endpoint, answering once all dependencies do
+	ReadyzPath = "/readyz"
+	// MetricsPath serves the prometheus metrics
+	MetricsPath = "/metrics"
+	// AdminPath is the route group of the admin endpoints, guarded by the admin token
+	AdminPath = "/admin"
+	// AdminLogLevelPath reads and changes the log level within AdminPath
+	AdminLogLevelPath = "/log-level"
+	// CacheRedis caches users in redis
+	CacheRedis = "redis"
+	// CacheMemory caches users in the memory of the instance
+	CacheMemory = "memory"
+	// ImageDBDynamo keeps image records in dynamoDB
+	ImageDBDynamo = "dynamodb"
+	// ImageDBMemory keeps image records in memory, they are lost on restart
+	ImageDBMemory = "memory"
+	// ImageStorageS3 keeps image files in S3
+	ImageStorageS3 = "s3"
+	// ImageStorageFS keeps image files in a local directory
+	ImageStorageFS = "fs"
+	// DefaultLocalStorageDir is the image directory with ImageStorageFS if none is configured
+	DefaultLocalStorageDir = "./data/images"
 	// QueryParamLastKey name of query param that holds last evaluated key
 	QueryParamLastKey = "lastKey"
 	// QueryParamlastKeyDate name of query param that holds last evaluated key date
 	QueryParamlastKeyDate = "lastKeyDate"
 	// QueryParamLimit name of query param that holds history limit
 	QueryParamLimit = "limit"
+	// PathParamAlbumID name of path param that holds the album id
+	PathParamAlbumID = "albumId"
+	// PathParamTag name of path param that holds the image tag
+	PathParamTag = "tag"
+	// PathParamShareID name of path param that holds the share id or public link token
+	PathParamShareID = "shareId"
+	// PathParamOwnerID name of path param that holds the id of the user owning a shared image
+	PathParamOwnerID = "ownerId"
+	// QueryParamFrom name of query param that holds the start of a date range
+	QueryParamFrom = "from"
+	// QueryParamTo name of query param that holds the end of a date range
+	QueryParamTo = "to"
+	// QueryParamExpires name of query param that holds the expiry of a signed local url
+	QueryParamExpires = "expires"
+	// QueryParamSignature name of query param that holds the signature of a signed local url
+	QueryParamSignature = "signature"
+	// PathParamKey name of path param that holds the key of a local image file
+	PathParamKey = "key"
+	// QueryParamImageID name of query param that holds an image id
+	QueryParamImageID = "imageId"
+	// PublicSharePath is the route of the public share links
+	PublicSharePath = "/api/v1/shares"
+	// LocalFilesPath is the route serving the signed local urls of ImageStorageFS
+	LocalFilesPath = "/api/v1/files"
 )
 
-// NewEnv creates a new instance of Env
-// tries to load the env variables from .env
-func NewEnv() *Env {
-	path, err := os.Getwd()
-	if err != nil {
-		log.Fatalf("error getting path")
+// Defaults returns the config of the mode before any file, env variable or flag is applied
+func Defaults(mode Mode) *Env {
+	return &Env{
+		Mode:                   mode,
+		DBPrimaryPool:          Pool{MaxOpenConns: DefaultDBPoolSize, MaxIdleConns: DefaultDBPoolSize},
+		DBReplicaPool:          Pool{MaxOpenConns: DefaultDBPoolSize, MaxIdleConns: DefaultDBPoolSize},
+		DBReplicaMaxLag:        DefaultDBReplicaMaxLag,
+		DBReplicaCheckInterval: DefaultDBReplicaCheckInterval,
+		DBTimeout:              DefaultDBTimeout,
+		ServerPort:             DefaultServerPort,
+		ServerReadTimeout:      DefaultServerReadTimeout,
+		ServerWriteTimeout:     DefaultServerWriteTimeout,
+		ServerIdleTimeout:      DefaultServerIdleTimeout,
+		ShutdownTimeout:        DefaultShutdownTimeout,
+		RedisTimeout:           DefaultRedisTimeout,
+		DynamoDBTimeout:        DefaultDynamoDBTimeout,
+		S3Timeout:              DefaultS3Timeout,
+		Cache:                  CacheRedis,
+		ImageDB:                ImageDBDynamo,
+		ImageStorage:           ImageStorageS3,
+		LocalStorageDir:        DefaultLocalStorageDir,
+		HealthCheckTimeout:     DefaultHealthCheckTimeout,
+		LogLevel:               DefaultLogLevel,
+		LogFormat:              LogFormatJSON,
+		LogSampling:            true,
+		TracingExporter:        TracingExporterNone,
+		OTLPEndpoint:           DefaultOTLPEndpoint,
+		TracingSampleRatio:     DefaultTracingSampleRatio,
+		ImageQuotaBytes:        DefaultImageQuotaBytes,
+		ImageQuotaCount:        DefaultImageQuotaCount,
+		MaxUploadBytes:         DefaultMaxUploadBytes,
+		SecretsRefreshInterval: DefaultSecretsRefreshInterval,
+		RateLimitEnabled:       true,
+		RateLimitUsers:         RateLimit{Requests: DefaultRateLimitRequests, Window: DefaultRateLimitWindow},
+		RateLimitImages:        RateLimit{Requests: DefaultRateLimitRequests, Window: DefaultRateLimitWindow},
+		RateLimitUploads:       RateLimit{Requests: DefaultRateLimitUploads, Window: DefaultRateLimitWindow},
+		RateLimitPublic:        RateLimit{Requests: DefaultRateLimitRequests, Window: DefaultRateLimitWindow},
+		CacheTTL:               DefaultCacheTTL,
+		CacheNotFoundTTL:       DefaultCacheNotFoundTTL,
+		CacheStaleTTL:          DefaultCacheStaleTTL,
+		CacheTTLJitter:         DefaultCacheTTLJitter,
+		IdempotencyKeyTTL:      DefaultIdempotencyKeyTTL,
+		IdempotencyLockTTL:     DefaultIdempotencyLockTTL,
 	}
-	dotenvError := godotenv.Load(fmt.Sprintf("%s/.env", path))
-	if dotenvError != nil {
-		log.Printf("error loading .env file, ignoring dotenv")
+}
+
+// useStandaloneBackends replaces every external dependency with one running inside the binary
+func (e *Env) useStandaloneBackends() {
+	if DBDriver(e.DBConnectionString) != DBDriverSQLite {
+		e.DBConnectionString = DefaultStandaloneDB
+		delete(e.refs, "db_connection_string")
 	}
+	e.DBReplicaConnectionStrings = nil
+	delete(e.refs, "db_replica_connection_strings")
+	e.Cache = CacheMemory
+	e.ImageDB = ImageDBMemory
+	e.ImageStorage = ImageStorageFS
+}
 
-	return &Env{
-		DBConnectionString:       os.Getenv("MysqlDB_Connection_String"),
-		ServerHost:               os.Getenv("Server_Host"),
-		ServerPort:               os.Getenv("Server_Port"),
-		RedisAddress:             os.Getenv("Redis_Address"),
-		DefaultAWSRegion:         os.Getenv("AWS_REGION"),
-		DynamoDBConnectionString: os.Getenv("DynamoDB_Connection_String"),
-		Environment:              os.Getenv("Environment"),
-		DynamoDBTable:            os.Getenv("DynamoDB_Table"),
-		AwsAccessKey:             os.Getenv("AWS_ACCESS_KEY_ID"),
-		AwsSecretAccessKey:       os.Getenv("AWS_SECRET_ACCESS_KEY"),
-		S3Bucket:                 os.Getenv("S3Bucket"),
-		S3Directory:              os.Getenv("S3Directory"),
+// DBDriver returns the user database of the connection string from its scheme, MySQL DSNs have none
+func DBDriver(dsn string) string {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return DBDriverPostgres
+	case strings.HasPrefix(dsn, "sqlite://"):
+		return DBDriverSQLite
+	default:
+		return DBDriverMySQL
 	}
 }
+
+// TrimDBScheme strips the mysql:// and sqlite:// schemes, their drivers only take plain DSNs
+func TrimDBScheme(dsn string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(dsn, "mysql://"), "sqlite://")
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rahul-aut-ind/service-user/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Flags holds the settings given on the command line, see RegisterFlags
+	Flags struct {
+		values map[string]string
+	}
+
+	// setting is a single value of Env, addressed by its yaml key with nested keys joined by a dot
+	setting struct {
+		key    string
+		envs   []string
+		secret string
+		index  []int
+	}
+
+	// loader applies the layers of Load to env, remembering which settings were set and every invalid value
+	loader struct {
+		env  *Env
+		set  map[string]bool
+		errs []error
+	}
+)
+
+const (
+	// EnvConfigFile is the env variable naming the yaml config file if no --config flag is given
+	EnvConfigFile = "Config_File"
+
+	secretValue = "true"
+	secretDSN   = "dsn"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load reads the config of the mode in layers, each overriding the one before:
+// the Defaults, the yaml file, the env variables, with a .env file in the working directory, and the flags.
+// A file of "" falls back to the EnvConfigFile env variable, with neither no file is read.
+// The secret references of the config are then resolved by r.
+// Load returns the config along with every invalid value and failed Validate check joined in one error,
+// so all of them can be reported at once.
+func Load(mode Mode, file string, flags *Flags, r *secrets.Resolver) (*Env, error) {
+	if wd, err := os.Getwd(); err == nil {
+		if err := godotenv.Load(filepath.Join(wd, ".env")); err != nil {
+			log.Printf("error loading .env file, ignoring dotenv")
+		}
+	}
+
+	l := &loader{env: Defaults(mode), set: map[string]bool{}}
+	if file == "" {
+		file = os.Getenv(EnvConfigFile)
+	}
+	if file != "" {
+		l.loadFile(file)
+	}
+	l.loadEnv()
+	if flags != nil {
+		l.loadFlags(flags)
+	}
+
+	if err := l.env.resolveSecrets(context.Background(), r); err != nil {
+		l.errs = append(l.errs, err)
+	}
+
+	if !l.set["auto_migrate"] {
+		l.env.AutoMigrate = l.env.Environment != ProductionEnvironment
+	}
+	if mode == ModeStandalone {
+		l.env.useStandaloneBackends()
+	}
+
+	return l.env, stderrors.Join(append(l.errs, l.env.Validate())...)
+}
+
+// RegisterFlags defines a flag on fs for every setting, named like its yaml key with dashes,
+// e.g. --db-timeout=10s or --db-primary-pool-max-open-conns=20
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{values: map[string]string{}}
+	for _, s := range settings() {
+		key := s.key
+		fs.Func(s.flagName(), "overrides "+s.envs[0], func(v string) error {
+			f.values[key] = v
+			return nil
+		})
+	}
+	return f
+}
+
+func (l *loader) loadFile(file string) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("config file: %w", err))
+		return
+	}
+
+	// unknown keys are rejected, a typo would otherwise silently leave the default in place
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(l.env); err != nil {
+		l.errs = append(l.errs, fmt.Errorf("config file %s: %w", file, err))
+		return
+	}
+
+	var keys map[string]interface{}
+	if err := yaml.Unmarshal(b, &keys); err == nil {
+		markSet(l.set, "", keys)
+	}
+}
+
+// markSet records the keys of the decoded yaml document m, nested keys joined by a dot
+func markSet(set map[string]bool, prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			markSet(set, prefix+k+".", nested)
+			continue
+		}
+		set[prefix+k] = true
+	}
+}
+
+func (l *loader) loadEnv() {
+	for _, s := range settings() {
+		for _, name := range s.envs {
+			if v, ok := os.LookupEnv(name); ok && v != "" {
+				l.apply(s, name, v)
+				break
+			}
+		}
+	}
+}
+
+func (l *loader) loadFlags(f *Flags) {
+	for _, s := range settings() {
+		if v, ok := f.values[s.key]; ok {
+			l.apply(s, "--"+s.flagName(), v)
+		}
+	}
+}
+
+// apply sets the setting to v read from source, keeping the previous value if v is invalid
+func (l *loader) apply(s setting, source, v string) {
+	if err := parseInto(l.env.field(s), v); err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: invalid value %q: %w", source, v, err))
+		return
+	}
+	l.set[s.key] = true
+}
+
+// parseInto sets the field to the text v, lists are comma separated with empty entries dropped
+func parseInto(field reflect.Value, v string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(v)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// field returns the addressable field of e holding the setting
+func (e *Env) field(s setting) reflect.Value {
+	return reflect.ValueOf(e).Elem().FieldByIndex(s.index)
+}
+
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+// settingByKey returns the setting with the yaml key
+func settingByKey(key string) (setting, bool) {
+	for _, s := range settings() {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// settings lists the settings of Env in the order of its fields
+var settings = sync.OnceValue(func() []setting {
+	return collectSettings(reflect.TypeOf(Env{}), "", "", nil)
+})
+
+func collectSettings(t reflect.Type, keyPrefix, envPrefix string, index []int) []setting {
+	var list []setting
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := f.Tag.Get("yaml")
+		if key == "-" || key == "" {
+			continue
+		}
+		idx := append(append([]int{}, index...), i)
+		envs := strings.Split(f.Tag.Get("env"), ",")
+
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			list = append(list, collectSettings(f.Type, keyPrefix+key+".", envPrefix+envs[0], idx)...)
+			continue
+		}
+		for j := range envs {
+			envs[j] = envPrefix + envs[j]
+		}
+		list = append(list, setting{key: keyPrefix + key, envs: envs, secret: f.Tag.Get("secret"), index: idx})
+	}
+	return list
+}
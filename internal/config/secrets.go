@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+
+	"github.com/rahul-aut-ind/service-user/internal/secrets"
+)
+
+// Refresh reads the secret references of the config again, returning a copy holding the current secrets.
+// A config not created by Load has no references and is returned as is.
+func (e *Env) Refresh(ctx context.Context) (*Env, error) {
+	if e.resolver == nil {
+		return e, nil
+	}
+
+	next := *e
+	for key, configured := range e.refs {
+		s, _ := settingByKey(key)
+		next.field(s).Set(reflect.ValueOf(configured))
+	}
+	if err := next.resolveSecrets(ctx, e.resolver); err != nil {
+		return nil, err
+	}
+	if next.Mode == ModeStandalone {
+		next.useStandaloneBackends()
+	}
+	return &next, nil
+}
+
+// resolveSecrets replaces the references of the secret settings with the secrets, keeping the configured
+// values for Refresh and Print
+func (e *Env) resolveSecrets(ctx context.Context, r *secrets.Resolver) error {
+	e.resolver = r
+	e.refs = map[string]interface{}{}
+
+	var errs []error
+	for _, s := range settings() {
+		if s.secret == "" {
+			continue
+		}
+		field := e.field(s)
+		switch configured := field.Interface().(type) {
+		case string:
+			e.refs[s.key] = configured
+			secret, err := r.Resolve(ctx, configured)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.envs[0], err))
+				continue
+			}
+			field.SetString(secret)
+		case []string:
+			e.refs[s.key] = configured
+			resolved := make([]string, len(configured))
+			for i, v := range configured {
+				secret, err := r.Resolve(ctx, v)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", s.envs[0], err))
+				}
+				resolved[i] = secret
+			}
+			field.Set(reflect.ValueOf(resolved))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// ref returns the reference configured for the secret setting, i is the index of a list item, -1 otherwise
+func (e *Env) ref(key string, i int) (string, bool) {
+	if e.resolver == nil {
+		return "", false
+	}
+	var configured string
+	switch v := e.refs[key].(type) {
+	case string:
+		configured = v
+	case []string:
+		if i < 0 || i >= len(v) {
+			return "", false
+		}
+		configured = v[i]
+	}
+	return configured, e.resolver.IsRef(configured)
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Validate checks that the config is complete for the backends it selects, returning every problem found
+// joined in one error, each naming the setting by its env variable and yaml key
+func (e *Env) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", settingName(key), fmt.Sprintf(format, args...)))
+		}
+	}
+	required := func(v, key, reason string) {
+		check(v != "", key, "is required %s", reason)
+	}
+	oneOf := func(v, key string, allowed ...string) {
+		for _, a := range allowed {
+			if v == a {
+				return
+			}
+		}
+		check(false, key, "is %q, must be one of %q", v, allowed)
+	}
+	positive := func(d time.Duration, key string) {
+		check(d > 0, key, "must be positive, is %s", d)
+	}
+
+	if e.Mode != ModeService && e.Mode != ModeStandalone {
+		errs = append(errs, fmt.Errorf("--mode: is %q, must be %s or %s", e.Mode, ModeService, ModeStandalone))
+	}
+
+	required(e.DBConnectionString, "db_connection_string", "for the user database")
+	check(e.DBPrimaryPool.MaxOpenConns > 0, "db_primary_pool.max_open_conns", "must be positive")
+	check(e.DBReplicaPool.MaxOpenConns > 0, "db_replica_pool.max_open_conns", "must be positive")
+	positive(e.DBTimeout, "db_timeout")
+	if len(e.DBReplicaConnectionStrings) > 0 {
+		positive(e.DBReplicaMaxLag, "db_replica_max_lag")
+		positive(e.DBReplicaCheckInterval, "db_replica_check_interval")
+	}
+
+	port, err := strconv.Atoi(e.ServerPort)
+	check(err == nil && port > 0 && port < 1<<16, "server_port", "is %q, must be a port number", e.ServerPort)
+	positive(e.ServerReadTimeout, "server_read_timeout")
+	positive(e.ServerWriteTimeout, "server_write_timeout")
+	positive(e.ServerIdleTimeout, "server_idle_timeout")
+	positive(e.ShutdownTimeout, "shutdown_timeout")
+	positive(e.HealthCheckTimeout, "health_check_timeout")
+
+	oneOf(e.Cache, "cache", CacheRedis, CacheMemory)
+	if e.Cache == CacheRedis {
+		required(e.RedisAddress, "redis_address", "with cache "+CacheRedis)
+		positive(e.RedisTimeout, "redis_timeout")
+	}
+
+	if e.ImageDB == ImageDBDynamo || e.ImageStorage == ImageStorageS3 {
+		required(e.DefaultAWSRegion, "aws_region", "with image_db "+ImageDBDynamo+" or image_storage "+ImageStorageS3)
+	}
+
+	oneOf(e.ImageDB, "image_db", ImageDBDynamo, ImageDBMemory)
+	if e.ImageDB == ImageDBDynamo {
+		reason := "with image_db " + ImageDBDynamo
+		required(e.DynamoDBTable, "dynamodb_table", reason)
+		required(e.DynamoDBAlbumTable, "dynamodb_album_table", reason)
+		required(e.DynamoDBShareTable, "dynamodb_share_table", reason)
+		positive(e.DynamoDBTimeout, "dynamodb_timeout")
+		checkEndpoint(check, e.DynamoDBEndpoint, "dynamodb_endpoint")
+	}
+
+	oneOf(e.ImageStorage, "image_storage", ImageStorageS3, ImageStorageFS)
+	switch e.ImageStorage {
+	case ImageStorageS3:
+		required(e.S3Bucket, "s3_bucket", "with image_storage "+ImageStorageS3)
+		positive(e.S3Timeout, "s3_timeout")
+		checkEndpoint(check, e.S3Endpoint, "s3_endpoint")
+	case ImageStorageFS:
+		required(e.LocalStorageDir, "local_storage_dir", "with image_storage "+ImageStorageFS)
+	}
+
+	_, err = zapcore.ParseLevel(e.LogLevel)
+	check(err == nil, "log_level", "is %q, must be debug, info, warn or error", e.LogLevel)
+	oneOf(e.LogFormat, "log_format", LogFormatJSON, LogFormatConsole)
+
+	oneOf(e.TracingExporter, "tracing_exporter", TracingExporterNone, TracingExporterOTLP, TracingExporterStdout)
+	if e.TracingExporter == TracingExporterOTLP {
+		required(e.OTLPEndpoint, "otlp_endpoint", "with tracing_exporter "+TracingExporterOTLP)
+	}
+	check(e.TracingSampleRatio >= 0 && e.TracingSampleRatio <= 1, "tracing_sample_ratio",
+		"must be between 0 and 1, is %g", e.TracingSampleRatio)
+
+	check(e.ImageQuotaBytes > 0, "image_quota_bytes", "must be positive")
+	check(e.ImageQuotaCount > 0, "image_quota_count", "must be positive")
+	check(e.MaxUploadBytes > 0, "max_upload_bytes", "must be positive")
+	if e.RateLimitEnabled {
+		limits := []struct {
+			key   string
+			limit RateLimit
+		}{
+			{"rate_limit_users", e.RateLimitUsers},
+			{"rate_limit_images", e.RateLimitImages},
+			{"rate_limit_uploads", e.RateLimitUploads},
+			{"rate_limit_public", e.RateLimitPublic},
+		}
+		for _, l := range limits {
+			check(l.limit.Requests > 0, l.key+".requests", "must be positive")
+			check(l.limit.Window >= time.Millisecond, l.key+".window", "must be at least 1ms, is %s", l.limit.Window)
+		}
+	}
+	check(e.CacheTTL > 0, "cache_ttl", "must be positive")
+	check(e.CacheNotFoundTTL >= 0, "cache_not_found_ttl", "must not be negative")
+	check(e.CacheStaleTTL >= 0, "cache_stale_ttl", "must not be negative")
+	check(e.CacheTTLJitter >= 0 && e.CacheTTLJitter < 1, "cache_ttl_jitter", "must be at least 0 and below 1, is %g", e.CacheTTLJitter)
+	check(e.IdempotencyKeyTTL > 0, "idempotency_key_ttl", "must be positive")
+	check(e.IdempotencyLockTTL > 0, "idempotency_lock_ttl", "must be positive")
+	check(e.SecretsRefreshInterval >= 0, "secrets_refresh_interval", "must not be negative")
+
+	return stderrors.Join(errs...)
+}
+
+// checkEndpoint accepts an empty endpoint, the one of the region is used then, or an absolute url
+func checkEndpoint(check func(bool, string, string, ...interface{}), endpoint, key string) {
+	if endpoint == "" {
+		return
+	}
+	u, err := url.Parse(endpoint)
+	check(err == nil && u.Scheme != "" && u.Host != "", key, "is %q, must be an absolute url", endpoint)
+}
+
+// settingName names the setting with the yaml key by its env variable and key
+func settingName(key string) string {
+	if s, ok := settingByKey(key); ok {
+		return fmt.Sprintf("%s (%s)", s.envs[0], key)
+	}
+	return key
+}
@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Local keeps secrets in memory, standing in for a secret manager in tests and local runs.
+// Set changes a secret like a rotation would.
+type Local struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+// SchemeLocal is the scheme the Local provider is usually registered for, e.g. local:db
+const SchemeLocal = "local"
+
+// NewLocal creates a Local provider holding a copy of the secrets by reference
+func NewLocal(secrets map[string]string) *Local {
+	l := &Local{secrets: make(map[string]string, len(secrets))}
+	for ref, v := range secrets {
+		l.secrets[ref] = v
+	}
+	return l
+}
+
+// Set stores the secret of the reference
+func (l *Local) Set(ref, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.secrets[ref] = value
+}
+
+// Resolve returns the secret stored for the reference
+func (l *Local) Resolve(_ context.Context, ref string) (string, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	v, ok := l.secrets[ref]
+	if !ok {
+		return "", fmt.Errorf("no local secret %s", ref)
+	}
+	return v, nil
+}
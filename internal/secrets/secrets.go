@@ -0,0 +1,101 @@
+// Package secrets resolves references to secrets kept outside of the config, like file:///run/secrets/db
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+type (
+	// Provider returns the current value of the secret a reference points to, the reference is passed
+	// without its scheme, e.g. ///run/secrets/db for file:///run/secrets/db
+	Provider interface {
+		Resolve(ctx context.Context, ref string) (string, error)
+	}
+
+	// Resolver resolves references of the form <scheme>:<ref> through the provider registered for the scheme.
+	// Values without a registered scheme, like plain DSNs, are not references and are returned as they are.
+	Resolver struct {
+		mu        sync.RWMutex
+		providers map[string]Provider
+	}
+
+	// File reads secrets from files, like the ones mounted by docker or kubernetes, e.g. file:///run/secrets/db
+	File struct{}
+
+	// Env reads secrets from env variables, e.g. env:DB_PASSWORD
+	Env struct{}
+)
+
+const (
+	// SchemeFile is the scheme of the File provider
+	SchemeFile = "file"
+	// SchemeEnv is the scheme of the Env provider
+	SchemeEnv = "env"
+)
+
+// NewResolver creates a Resolver with the File and the Env provider
+func NewResolver() *Resolver {
+	return &Resolver{providers: map[string]Provider{
+		SchemeFile: File{},
+		SchemeEnv:  Env{},
+	}}
+}
+
+// Register adds a provider for the scheme, replacing the one registered before
+func (r *Resolver) Register(scheme string, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[scheme] = p
+}
+
+// IsRef reports whether the value is a reference to a secret
+func (r *Resolver) IsRef(value string) bool {
+	_, _, ok := r.provider(value)
+	return ok
+}
+
+// Resolve returns the secret the value refers to, or the value itself if it is no reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	p, ref, ok := r.provider(value)
+	if !ok {
+		return value, nil
+	}
+	secret, err := p.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", value, err)
+	}
+	return secret, nil
+}
+
+func (r *Resolver) provider(value string) (Provider, string, bool) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, "", false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[scheme]
+	return p, ref, ok
+}
+
+// Resolve reads the file, a trailing line break is dropped
+func (File) Resolve(_ context.Context, ref string) (string, error) {
+	b, err := os.ReadFile(strings.TrimPrefix(ref, "//"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// Resolve reads the env variable, which must be set
+func (Env) Resolve(_ context.Context, ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("env variable %s is not set", ref)
+	}
+	return v, nil
+}
@@ -6,6 +6,8 @@ import (
 	gin "github.com/gin-gonic/gin"
 	mock "github.com/stretchr/testify/mock"
 
+	io "io"
+
 	time "time"
 )
 
@@ -118,6 +120,11 @@ func (_m *Context) GetHeader(key string) string {
 	return r0
 }
 
+// Header provides a mock function with given fields: key, value
+func (_m *Context) Header(key string, value string) {
+	_m.Called(key, value)
+}
+
 // JSON provides a mock function with given fields: code, obj
 func (_m *Context) JSON(code int, obj interface{}) {
 	_m.Called(code, obj)
@@ -159,6 +166,11 @@ func (_m *Context) Query(key string) string {
 	return r0
 }
 
+// Redirect provides a mock function with given fields: code, location
+func (_m *Context) Redirect(code int, location string) {
+	_m.Called(code, location)
+}
+
 // ShouldBindJSON provides a mock function with given fields: obj
 func (_m *Context) ShouldBindJSON(obj interface{}) error {
 	ret := _m.Called(obj)
@@ -177,6 +189,24 @@ func (_m *Context) ShouldBindJSON(obj interface{}) error {
 	return r0
 }
 
+// Stream provides a mock function with given fields: step
+func (_m *Context) Stream(step func(io.Writer) bool) bool {
+	ret := _m.Called(step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stream")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(func(io.Writer) bool) bool); ok {
+		r0 = rf(step)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // Value provides a mock function with given fields: key
 func (_m *Context) Value(key interface{}) interface{} {
 	ret := _m.Called(key)
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/rahul-aut-ind/service-user/domain/models"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,9 @@ type DBRepo struct {
 	mock.Mock
 }
 
-// CreateRecord provides a mock function with given fields: u
-func (_m *DBRepo) CreateRecord(u *models.User) (*models.User, error) {
-	ret := _m.Called(u)
+// CreateRecord provides a mock function with given fields: ctx, u
+func (_m *DBRepo) CreateRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	ret := _m.Called(ctx, u)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateRecord")
@@ -22,19 +24,19 @@ func (_m *DBRepo) CreateRecord(u *models.User) (*models.User, error) {
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models.User) (*models.User, error)); ok {
-		return rf(u)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) (*models.User, error)); ok {
+		return rf(ctx, u)
 	}
-	if rf, ok := ret.Get(0).(func(*models.User) *models.User); ok {
-		r0 = rf(u)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+		r0 = rf(ctx, u)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models.User) error); ok {
-		r1 = rf(u)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, u)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -42,9 +44,9 @@ func (_m *DBRepo) CreateRecord(u *models.User) (*models.User, error) {
 	return r0, r1
 }
 
-// DeleteRecord provides a mock function with given fields: u
-func (_m *DBRepo) DeleteRecord(u *models.User) (*models.User, error) {
-	ret := _m.Called(u)
+// DeleteRecord provides a mock function with given fields: ctx, u
+func (_m *DBRepo) DeleteRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	ret := _m.Called(ctx, u)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteRecord")
@@ -52,19 +54,19 @@ func (_m *DBRepo) DeleteRecord(u *models.User) (*models.User, error) {
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models.User) (*models.User, error)); ok {
-		return rf(u)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) (*models.User, error)); ok {
+		return rf(ctx, u)
 	}
-	if rf, ok := ret.Get(0).(func(*models.User) *models.User); ok {
-		r0 = rf(u)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+		r0 = rf(ctx, u)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models.User) error); ok {
-		r1 = rf(u)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, u)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,9 +74,9 @@ func (_m *DBRepo) DeleteRecord(u *models.User) (*models.User, error) {
 	return r0, r1
 }
 
-// FindRecord provides a mock function with given fields: id
-func (_m *DBRepo) FindRecord(id string) (*models.User, error) {
-	ret := _m.Called(id)
+// FindRecord provides a mock function with given fields: ctx, id
+func (_m *DBRepo) FindRecord(ctx context.Context, id string) (*models.User, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindRecord")
@@ -82,19 +84,19 @@ func (_m *DBRepo) FindRecord(id string) (*models.User, error) {
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.User, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.User, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -102,9 +104,9 @@ func (_m *DBRepo) FindRecord(id string) (*models.User, error) {
 	return r0, r1
 }
 
-// ListRecords provides a mock function with given fields:
-func (_m *DBRepo) ListRecords() ([]models.User, error) {
-	ret := _m.Called()
+// ListRecords provides a mock function with given fields: ctx
+func (_m *DBRepo) ListRecords(ctx context.Context) ([]models.User, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListRecords")
@@ -112,19 +114,19 @@ func (_m *DBRepo) ListRecords() ([]models.User, error) {
 
 	var r0 []models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]models.User, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.User, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() []models.User); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []models.User); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -132,9 +134,9 @@ func (_m *DBRepo) ListRecords() ([]models.User, error) {
 	return r0, r1
 }
 
-// UpdateRecord provides a mock function with given fields: u
-func (_m *DBRepo) UpdateRecord(u *models.User) (*models.User, error) {
-	ret := _m.Called(u)
+// UpdateRecord provides a mock function with given fields: ctx, u
+func (_m *DBRepo) UpdateRecord(ctx context.Context, u *models.User) (*models.User, error) {
+	ret := _m.Called(ctx, u)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateRecord")
@@ -142,19 +144,19 @@ func (_m *DBRepo) UpdateRecord(u *models.User) (*models.User, error) {
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models.User) (*models.User, error)); ok {
-		return rf(u)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) (*models.User, error)); ok {
+		return rf(ctx, u)
 	}
-	if rf, ok := ret.Get(0).(func(*models.User) *models.User); ok {
-		r0 = rf(u)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+		r0 = rf(ctx, u)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models.User) error); ok {
-		r1 = rf(u)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, u)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -2,9 +2,14 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -21,16 +26,111 @@ type LogHandler interface {
 
 type Logger struct {
 	*zap.SugaredLogger
+	level zap.AtomicLevel
 }
 
-// New Creates new instance of Logger
+// Options configures the output of a Logger
+type Options struct {
+	// Level is the minimum level logged, debug, info, warn or error
+	Level string
+	// Format is FormatJSON or FormatConsole
+	Format string
+	// Sampling keeps the first 100 lines with the same message and level in a second,
+	// then every 100th, so a hot error path can not flood the logs
+	Sampling bool
+}
+
+const (
+	// FormatJSON writes a json object per line, for log shippers
+	FormatJSON = "json"
+	// FormatConsole writes human readable lines, for local use
+	FormatConsole = "console"
+
+	sampleTick       = time.Second
+	sampleFirst      = 100
+	sampleThereafter = 100
+)
+
+// New Creates new instance of Logger, logging json from level info with sampling
 func New() *Logger {
-	var logger *zap.Logger
+	l, _ := NewWithOptions(Options{Level: zapcore.InfoLevel.String(), Format: FormatJSON, Sampling: true})
+	return l
+}
+
+// NewWithOptions creates a Logger writing to stderr as configured. Emails, addresses and tokens
+// are masked in every line, see redactingCore.
+func NewWithOptions(o Options) (*Logger, error) {
+	level, err := zap.ParseAtomicLevel(o.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var enc zapcore.Encoder
+	switch o.Format {
+	case FormatJSON:
+		enc = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	case FormatConsole:
+		enc = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	default:
+		return nil, fmt.Errorf("unknown log format %q", o.Format)
+	}
+
+	out := zapcore.Lock(os.Stderr)
+	var core zapcore.Core = &redactingCore{zapcore.NewCore(enc, out, level)}
+	if o.Sampling {
+		core = zapcore.NewSamplerWithOptions(core, sampleTick, sampleFirst, sampleThereafter)
+	}
+
+	z := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel), zap.ErrorOutput(out))
+	return &Logger{SugaredLogger: z.Sugar(), level: level}, nil
+}
 
-	logger, _ = zap.NewProduction()
-	sugarLogger := logger.Sugar()
+// LevelHandler reads the level with GET and changes it at runtime with PUT, both as {"level":"debug"}.
+// The change applies to every logger derived from l.
+func (l *Logger) LevelHandler() http.Handler {
+	return l.level
+}
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx holding the request-scoped logger l, see For
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// For returns the request-scoped logger held by ctx, so a log line can be tied to its request.
+// Outside of a request it falls back to l with the trace of ctx, if any.
+func (l *Logger) For(ctx context.Context) *Logger {
+	if scoped, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		return scoped
+	}
+	return l.WithTrace(ctx)
+}
 
-	return &Logger{sugarLogger}
+// With adds key value pairs to the fields of every line logged
+func (l *Logger) With(args ...interface{}) *Logger {
+	return &Logger{SugaredLogger: l.SugaredLogger.With(args...), level: l.level}
+}
+
+// WithTrace adds the trace and span id of the span in ctx to the log fields, so the logs of a
+// request can be found from its trace. Without a span the logger is returned as is.
+func (l *Logger) WithTrace(ctx context.Context) *Logger {
+	fields := traceFields(ctx)
+	if fields == nil {
+		return l
+	}
+	return &Logger{SugaredLogger: l.Desugar().With(fields...).Sugar(), level: l.level}
+}
+
+func traceFields(ctx context.Context) []zapcore.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zapcore.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
 }
 
 // DefaultLogger receives the default log of the GIN framework
@@ -48,13 +148,15 @@ func (l *Logger) DefaultLogger() gin.HandlerFunc {
 			zap.String("ip", c.ClientIP()),
 			zap.String("user-agent", c.Request.UserAgent()),
 		}
+		// logs with the request id and trace of the request, if the middlewares setting them ran before
+		log := l.For(c.Request.Context()).Desugar()
 		if len(c.Errors) > 0 {
 			// Append error field if this is an erroneous request.
 			for _, e := range c.Errors.Errors() {
-				l.Desugar().Error(e, fields...)
+				log.Error(e, fields...)
 			}
 		} else {
-			l.Desugar().Info(path, fields...)
+			log.Info(path, fields...)
 		}
 	}
 }
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObservedLogger() (*Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	return &Logger{SugaredLogger: zap.New(&redactingCore{core}).Sugar()}, logs
+}
+
+func TestRedaction_MasksMessages(t *testing.T) {
+	l, logs := newObservedLogger()
+	u := models.User{ID: 7, Name: "Jane", Email: "jane@example.com", Address: "1 Main Street"}
+
+	l.Debugf("inserting record %v", u)
+	l.Infof("login of jane.doe@example.com with Authorization: Bearer abc.def-123")
+	l.Infof("callback ?id_token=eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig&state=1")
+
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "inserting record {ID:7 Name:Jane Email:[REDACTED] Address:[REDACTED] Age:0}", entries[0].Message)
+	assert.Equal(t, "login of [REDACTED] with Authorization: Bearer [REDACTED]", entries[1].Message)
+	assert.NotContains(t, entries[2].Message, "eyJ")
+	assert.Contains(t, entries[2].Message, "state=1")
+}
+
+func TestRedaction_MasksFields(t *testing.T) {
+	l, logs := newObservedLogger()
+
+	l.With("user_email", "jane@example.com").Infow("saved",
+		"address", "1 Main Street",
+		"x-id-token", "opaque",
+		"note", "contact jane@example.com",
+		"err", errors.New("duplicate jane@example.com"),
+		"user", models.User{ID: 7, Email: "jane@example.com"},
+		"status", 200,
+	)
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, Redacted, fields["user_email"])
+	assert.Equal(t, Redacted, fields["address"])
+	assert.Equal(t, Redacted, fields["x-id-token"])
+	assert.Equal(t, "contact [REDACTED]", fields["note"])
+	assert.Equal(t, "duplicate [REDACTED]", fields["err"])
+	assert.NotContains(t, fields["user"], "jane@")
+	assert.EqualValues(t, 200, fields["status"])
+}
+
+func TestNewWithOptions_RejectsUnknownSettings(t *testing.T) {
+	_, err := NewWithOptions(Options{Level: "loud", Format: FormatJSON})
+	assert.Error(t, err)
+
+	_, err = NewWithOptions(Options{Level: "info", Format: "xml"})
+	assert.Error(t, err)
+
+	l, err := NewWithOptions(Options{Level: "warn", Format: FormatConsole})
+	require.NoError(t, err)
+	assert.False(t, l.Desugar().Core().Enabled(zapcore.InfoLevel))
+}
+
+func TestLevelHandler_ChangesLevelOfDerivedLoggers(t *testing.T) {
+	l, err := NewWithOptions(Options{Level: "info", Format: FormatJSON, Sampling: true})
+	require.NoError(t, err)
+	scoped := l.With("request_id", "1")
+	require.False(t, scoped.Desugar().Core().Enabled(zapcore.DebugLevel))
+
+	rec := httptest.NewRecorder()
+	l.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"debug"}`)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, scoped.Desugar().Core().Enabled(zapcore.DebugLevel))
+}
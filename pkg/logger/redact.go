@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the values masked in the logs
+const Redacted = "[REDACTED]"
+
+var (
+	// sensitiveKeys mask the whole value of fields whose key contains one of them
+	sensitiveKeys = []string{"email", "address", "token", "password", "secret", "authorization"}
+
+	// sensitivePatterns are masked wherever they show up in a message or a string value
+	sensitivePatterns = []struct {
+		re   *regexp.Regexp
+		repl string
+	}{
+		{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), Redacted},
+		{regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/-]+=*`), "${1}" + Redacted},
+		{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted},
+		{regexp.MustCompile(`(?i)\b((?:id[_-]?)?token|password|secret)([=:]\s*)[^\s,&"}]+`), "${1}${2}" + Redacted},
+	}
+)
+
+// redactingCore masks emails, addresses and tokens before an entry is written, whatever logged them
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	e.Message = redactString(e.Message)
+	return c.Core.Write(e, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = redactField(f)
+	}
+	return redacted
+}
+
+func redactField(f zapcore.Field) zapcore.Field {
+	key := strings.ToLower(f.Key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return zap.String(f.Key, Redacted)
+		}
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = redactString(f.String)
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return zap.String(f.Key, redactString(err.Error()))
+		}
+	case zapcore.StringerType, zapcore.ReflectType:
+		// structs are flattened to text, which loses their shape but not their secrets
+		return zap.String(f.Key, redactString(fmt.Sprintf("%+v", f.Interface)))
+	}
+	return f
+}
+
+func redactString(s string) string {
+	for _, p := range sensitivePatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package imageservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+)
+
+type (
+	AlbumService interface {
+		CreateAlbum(ctx context.Context, uID, name string) (*models.AlbumResponse, error)
+		GetAllAlbums(ctx context.Context, uID string) (*models.AlbumListResponse, error)
+		RenameAlbum(ctx context.Context, uID, albumID, name string) (*models.AlbumResponse, error)
+		DeleteAlbum(ctx context.Context, uID, albumID string) error
+		AddImageToAlbum(ctx context.Context, uID, albumID, imageID string) error
+		RemoveImageFromAlbum(ctx context.Context, uID, albumID, imageID string) error
+	}
+)
+
+func (s *Service) CreateAlbum(ctx context.Context, uID, name string) (*models.AlbumResponse, error) {
+	albumID, err := uuid.NewUUID()
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("uuid generation failed"))
+	}
+
+	now := time.Now()
+	a := &models.Album{
+		UserID:    uID,
+		AlbumID:   albumID.String(),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = s.db.CreateAlbum(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAlbumResponse(a), nil
+}
+
+func (s *Service) GetAllAlbums(ctx context.Context, uID string) (*models.AlbumListResponse, error) {
+	albums, err := s.db.ListAlbums(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]models.AlbumResponse, 0, len(albums))
+	for i := range albums {
+		r = append(r, *toAlbumResponse(&albums[i]))
+	}
+
+	return &models.AlbumListResponse{Albums: r}, nil
+}
+
+func (s *Service) RenameAlbum(ctx context.Context, uID, albumID, name string) (*models.AlbumResponse, error) {
+	a, err := s.db.RenameAlbum(ctx, uID, albumID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAlbumResponse(a), nil
+}
+
+func (s *Service) DeleteAlbum(ctx context.Context, uID, albumID string) error {
+	return s.db.DeleteAlbum(ctx, uID, albumID)
+}
+
+func (s *Service) AddImageToAlbum(ctx context.Context, uID, albumID, imageID string) error {
+	if _, err := s.db.GetAlbum(ctx, uID, albumID); err != nil {
+		return err
+	}
+
+	return s.db.AddImageToAlbum(ctx, uID, imageID, albumID)
+}
+
+func (s *Service) RemoveImageFromAlbum(ctx context.Context, uID, albumID, imageID string) error {
+	if _, err := s.db.GetAlbum(ctx, uID, albumID); err != nil {
+		return err
+	}
+
+	return s.db.RemoveImageFromAlbum(ctx, uID, imageID, albumID)
+}
+
+func toAlbumResponse(a *models.Album) *models.AlbumResponse {
+	return &models.AlbumResponse{
+		AlbumID:   a.AlbumID,
+		Name:      a.Name,
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
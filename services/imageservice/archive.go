@@ -0,0 +1,142 @@
+package imageservice
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+)
+
+const (
+	// ArchiveIndexFile is the name of the json index inside an image archive
+	ArchiveIndexFile  = "index.json"
+	archiveTimeLayout = "2006-01-02_15-04-05"
+)
+
+// WriteArchive streams a zip of the user's images to w. The images are read one at a time,
+// so memory use does not grow with their size or number. The index lists the images actually written
+// and is added as the last file, once they are all in. Until then its entries are kept in a temp file.
+func (s *Service) WriteArchive(ctx context.Context, req models.ArchiveInput, w io.Writer) error {
+	index, err := newArchiveIndex()
+	if err != nil {
+		return fmt.Errorf("error creating archive index: %w", err)
+	}
+	defer index.close()
+
+	zw := zip.NewWriter(w)
+	err = s.db.IterateImages(ctx, req.UserID, req.From, req.To, func(ui *models.UserImage) error {
+		written, err := s.writeArchiveImage(ctx, zw, ui)
+		if err != nil || !written {
+			return err
+		}
+		return index.add(models.ArchiveIndexEntry{
+			File:    archiveFileName(ui),
+			ImageID: ui.ImageID,
+			TakenAt: ui.TakenAt,
+			Size:    ui.Size,
+			Tags:    ui.Tags,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := index.writeTo(zw); err != nil {
+		return fmt.Errorf("error writing archive index: %w", err)
+	}
+
+	return zw.Close()
+}
+
+// archiveIndex collects the json array of the index entries in a temp file
+type archiveIndex struct {
+	f       *os.File
+	entries int
+}
+
+func newArchiveIndex() (*archiveIndex, error) {
+	f, err := os.CreateTemp("", "archive-index-*.json")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString("["); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+	return &archiveIndex{f: f}, nil
+}
+
+func (a *archiveIndex) add(entry models.ArchiveIndexEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if a.entries > 0 {
+		data = append([]byte{','}, data...)
+	}
+	if _, err := a.f.Write(data); err != nil {
+		return fmt.Errorf("error adding image %s to archive index: %w", entry.ImageID, err)
+	}
+	a.entries++
+	return nil
+}
+
+// writeTo closes the array and copies it into the archive as its index file
+func (a *archiveIndex) writeTo(zw *zip.Writer) error {
+	if _, err := a.f.WriteString("]\n"); err != nil {
+		return err
+	}
+	if _, err := a.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	f, err := zw.Create(ArchiveIndexFile)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, a.f)
+	return err
+}
+
+func (a *archiveIndex) close() {
+	_ = a.f.Close()
+	_ = os.Remove(a.f.Name())
+}
+
+// writeArchiveImage adds the image to the archive, reporting false if it was left out
+func (s *Service) writeArchiveImage(ctx context.Context, zw *zip.Writer, ui *models.UserImage) (bool, error) {
+	obj, err := s.s3.Get(ctx, ui.Path)
+	if err != nil {
+		// an image without its object is left out rather than failing the whole archive
+		s.log.For(ctx).Warnf("skipping image %s of user %s in archive :: %v", ui.ImageID, ui.UserID, err)
+		return false, nil
+	}
+	defer func() {
+		_ = obj.Close()
+	}()
+
+	f, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     archiveFileName(ui),
+		Method:   zip.Store, // images are already compressed
+		Modified: ui.TakenAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := io.Copy(f, obj); err != nil {
+		return false, fmt.Errorf("error writing image %s to archive: %w", ui.ImageID, err)
+	}
+
+	return true, nil
+}
+
+// archiveFileName names an image by the time it was taken, the id keeps names unique
+func archiveFileName(ui *models.UserImage) string {
+	return fmt.Sprintf("%s_%s%s", ui.TakenAt.UTC().Format(archiveTimeLayout), ui.ImageID, path.Ext(ui.Path))
+}
@@ -0,0 +1,166 @@
+package imageservice
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"github.com/rahul-aut-ind/service-user/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	archiveDB struct {
+		dynamorepo.DataHandler
+		images []models.UserImage
+		// from and to are the range the images were queried with
+		from, to *time.Time
+	}
+
+	archiveS3 struct {
+		s3repo.S3Handler
+		objects map[string]string
+	}
+)
+
+func (db *archiveDB) IterateImages(_ context.Context, _ string, from, to *time.Time, fn func(ui *models.UserImage) error) error {
+	db.from, db.to = from, to
+	for i := range db.images {
+		if !models.TakenBetween(db.images[i].TakenAt, from, to) {
+			continue
+		}
+		if err := fn(&db.images[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *archiveS3) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("NoSuchKey: %s", key)
+	}
+	return io.NopCloser(strings.NewReader(object)), nil
+}
+
+func readArchiveIndex(t *testing.T, zr *zip.Reader) []models.ArchiveIndexEntry {
+	t.Helper()
+	last := zr.File[len(zr.File)-1]
+	assert.Equal(t, ArchiveIndexFile, last.Name, "the index is the last file")
+	f, err := last.Open()
+	assert.Nil(t, err)
+	var index []models.ArchiveIndexEntry
+	assert.Nil(t, json.NewDecoder(f).Decode(&index))
+	return index
+}
+
+func TestService_WriteArchive_FiltersByDateAndWritesIndex(t *testing.T) {
+	day1 := time.Date(2024, 11, 10, 8, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, 11, 12, 9, 30, 0, 0, time.UTC)
+	db := &archiveDB{images: []models.UserImage{
+		{UserID: "11", ImageID: "aaaa", Path: "story-images/11/aaaa.jpg", TakenAt: day1, Tags: []string{"beach"}},
+		{UserID: "11", ImageID: "bbbb", Path: "story-images/11/bbbb.jpg", TakenAt: day2},
+	}}
+	s3 := &archiveS3{objects: map[string]string{
+		"story-images/11/aaaa.jpg": "first image",
+		"story-images/11/bbbb.jpg": "second image",
+	}}
+	testService := New(db, s3, &config.Env{}, logger.New())
+
+	from := time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC)
+	buf := &bytes.Buffer{}
+
+	// When
+	err := testService.WriteArchive(context.Background(), models.ArchiveInput{UserID: "11", From: &from}, buf)
+
+	// Then
+	assert.Nil(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, &from, db.from, "the range is queried, not filtered after")
+	assert.Equal(t, 2, len(zr.File))
+	assert.Equal(t, "2024-11-12_09-30-00_bbbb.jpg", zr.File[0].Name)
+
+	index := readArchiveIndex(t, zr)
+	assert.Equal(t, 1, len(index))
+	assert.Equal(t, "bbbb", index[0].ImageID)
+	assert.Equal(t, zr.File[0].Name, index[0].File)
+
+	f, _ := zr.File[0].Open()
+	content, _ := io.ReadAll(f)
+	assert.Equal(t, "second image", string(content))
+}
+
+func TestService_WriteArchive_IndexListsOnlyWrittenImages(t *testing.T) {
+	day := time.Date(2024, 11, 10, 8, 0, 0, 0, time.UTC)
+	db := &archiveDB{images: []models.UserImage{
+		{UserID: "11", ImageID: "aaaa", Path: "story-images/11/aaaa.jpg", TakenAt: day},
+		{UserID: "11", ImageID: "bbbb", Path: "story-images/11/bbbb.jpg", TakenAt: day.Add(time.Hour)},
+	}}
+	// the object of bbbb is missing
+	s3 := &archiveS3{objects: map[string]string{"story-images/11/aaaa.jpg": "first image"}}
+	testService := New(db, s3, &config.Env{}, logger.New())
+	buf := &bytes.Buffer{}
+
+	// When
+	err := testService.WriteArchive(context.Background(), models.ArchiveInput{UserID: "11"}, buf)
+
+	// Then
+	assert.Nil(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(zr.File))
+	index := readArchiveIndex(t, zr)
+	assert.Equal(t, 1, len(index))
+	assert.Equal(t, "aaaa", index[0].ImageID)
+}
+
+func TestService_WriteArchive_IndexIsKeptInATempFileRemovedAfter(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	day := time.Date(2024, 11, 10, 8, 0, 0, 0, time.UTC)
+	db := &archiveDB{}
+	s3 := &archiveS3{objects: map[string]string{}}
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprintf("%04d", i)
+		p := "story-images/11/" + id + ".jpg"
+		db.images = append(db.images, models.UserImage{UserID: "11", ImageID: id, Path: p, TakenAt: day.Add(time.Duration(i) * time.Minute)})
+		s3.objects[p] = "image"
+	}
+	testService := New(db, s3, &config.Env{}, logger.New())
+
+	for _, images := range [][]models.UserImage{db.images, nil} {
+		db.images = images
+		buf := &bytes.Buffer{}
+
+		// When
+		err := testService.WriteArchive(context.Background(), models.ArchiveInput{UserID: "11"}, buf)
+
+		// Then
+		assert.Nil(t, err)
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.Nil(t, err)
+		index := readArchiveIndex(t, zr)
+		assert.Equal(t, len(images), len(index))
+		if len(images) > 0 {
+			assert.Equal(t, "0049", index[49].ImageID)
+		}
+		assert.NotNil(t, index, "an empty index is an empty list")
+
+		left, err := os.ReadDir(tmp)
+		assert.Nil(t, err)
+		assert.Empty(t, left, "the temp file of the index is removed")
+	}
+}
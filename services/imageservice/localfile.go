@@ -0,0 +1,26 @@
+package imageservice
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/fsrepo"
+)
+
+// OpenLocalFile opens an image behind a signed local url, only served when images are stored on the local filesystem
+func (s *Service) OpenLocalFile(ctx context.Context, key, expires, signature string) (io.ReadCloser, error) {
+	opener, ok := s.s3.(fsrepo.SignedFileOpener)
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("file not found"))
+	}
+
+	f, err := opener.OpenSigned(ctx, key, expires, signature)
+	if err != nil {
+		// a bad signature and a missing file look the same to the caller
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("file not found"))
+	}
+
+	return f, nil
+}
@@ -2,7 +2,10 @@ package imageservice
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,39 +14,63 @@ import (
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/dynamorepo"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/repositories/s3repo"
 	"github.com/rahul-aut-ind/service-user/interfaceadapters/requestparser"
+	"github.com/rahul-aut-ind/service-user/internal/config"
 	"github.com/rahul-aut-ind/service-user/pkg/logger"
 	"golang.org/x/sync/errgroup"
 )
 
 type (
 	UserImageService interface {
-		SaveUserImage(uID string, req *requestparser.MultiPartData) (*models.UploadResponse, error)
-		GetAllUserImages(req models.PaginatedInput) (*models.PaginatedImageResponse, error)
-		GetByUserIDImageID(uID, imageID string) (*models.ImageResponse, error)
-		DeleteByUserIDImageID(uID, imageID string) error
-		DeleteAllByUserID(uID string) error
+		SaveUserImage(ctx context.Context, uID string, req *requestparser.MultiPartData) (*models.UploadResponse, error)
+		GetAllUserImages(ctx context.Context, req models.PaginatedInput) (*models.PaginatedImageResponse, error)
+		GetByUserIDImageID(ctx context.Context, uID, imageID string) (*models.ImageResponse, error)
+		DeleteByUserIDImageID(ctx context.Context, uID, imageID string) error
+		DeleteAllByUserID(ctx context.Context, uID string) error
+		SetUserImageTags(ctx context.Context, uID, imageID string, tags []string) (*models.ImageResponse, error)
+		GetUsage(ctx context.Context, uID string) (*models.UsageResponse, error)
+		WriteArchive(ctx context.Context, req models.ArchiveInput, w io.Writer) error
+		OpenLocalFile(ctx context.Context, key, expires, signature string) (io.ReadCloser, error)
+		AlbumService
+		ShareService
 	}
 
 	Service struct {
-		db  dynamorepo.DataHandler
-		s3  s3repo.S3Handler
-		log *logger.Logger
+		db    dynamorepo.DataHandler
+		s3    s3repo.S3Handler
+		quota models.Quota
+		log   *logger.Logger
 	}
 )
 
-func New(db dynamorepo.DataHandler, s3 s3repo.S3Handler, l *logger.Logger) *Service {
-	return &Service{db, s3, l}
+func New(db dynamorepo.DataHandler, s3 s3repo.S3Handler, env *config.Env, l *logger.Logger) *Service {
+	return &Service{
+		db:    db,
+		s3:    s3,
+		quota: models.Quota{MaxBytes: env.ImageQuotaBytes, MaxImages: env.ImageQuotaCount},
+		log:   l,
+	}
 }
 
-func (s *Service) SaveUserImage(uID string, req *requestparser.MultiPartData) (*models.UploadResponse, error) {
+func (s *Service) SaveUserImage(ctx context.Context, uID string, req *requestparser.MultiPartData) (*models.UploadResponse, error) {
 
 	imageID, err := uuid.NewUUID()
 	if err != nil {
 		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("uuid generation failed"))
 	}
 
-	s3Path, err := s.s3.Save(uID, imageID, req.Image.Ext, &req.Image.Bytes)
+	size := int64(len(req.Image.Bytes))
+	err = s.db.ReserveUsage(ctx, uID, size, s.quota)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Path, err := s.s3.Save(ctx, uID, imageID, req.Image.Ext, &req.Image.Bytes)
 	if err != nil {
+		s.releaseUsage(ctx, uID, size)
+		var storageErr *s3repo.StorageError
+		if stderrors.As(err, &storageErr) && storageErr.Unavailable {
+			return nil, errors.New(errors.ErrCodeServiceUnavailable, fmt.Errorf("image storage is unavailable, try again later"))
+		}
 		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error uploading image to S3"))
 	}
 
@@ -54,18 +81,28 @@ func (s *Service) SaveUserImage(uID string, req *requestparser.MultiPartData) (*
 		IsDeleted: false,
 		TakenAt:   req.Metadata.TakenAt,
 		UpdatedAt: time.Now(),
+		Size:      size,
+		Tags:      normalizeTags(req.Metadata.Tags),
 	}
 
-	err = s.db.AddImage(ui)
+	err = s.db.AddImage(ctx, ui)
 	if err != nil {
+		s.releaseUsage(ctx, uID, size)
 		return nil, err
 	}
 
 	return &models.UploadResponse{ID: imageID.String()}, nil
 }
 
-func (s *Service) GetAllUserImages(req models.PaginatedInput) (*models.PaginatedImageResponse, error) {
-	images, err := s.db.GetAllImagesPaginated(req)
+func (s *Service) GetAllUserImages(ctx context.Context, req models.PaginatedInput) (*models.PaginatedImageResponse, error) {
+	if req.AlbumID != "" {
+		if _, err := s.db.GetAlbum(ctx, req.UserID, req.AlbumID); err != nil {
+			return nil, err
+		}
+	}
+	req.Tag = normalizeTag(req.Tag)
+
+	images, err := s.db.GetAllImagesPaginated(ctx, req)
 
 	if err != nil {
 		return nil, err
@@ -73,12 +110,7 @@ func (s *Service) GetAllUserImages(req models.PaginatedInput) (*models.Paginated
 
 	r := make([]models.ImageResponse, 0, len(images.UserImages))
 	for i := range images.UserImages {
-		res := models.ImageResponse{
-			ImageID: images.UserImages[i].ImageID,
-			TakenAt: images.UserImages[i].TakenAt,
-			Path:    images.UserImages[i].Path,
-		}
-		r = append(r, res)
+		r = append(r, *toImageResponse(&images.UserImages[i]))
 	}
 
 	return &models.PaginatedImageResponse{
@@ -87,30 +119,100 @@ func (s *Service) GetAllUserImages(req models.PaginatedInput) (*models.Paginated
 	}, nil
 }
 
-func (s *Service) GetByUserIDImageID(uID, imageID string) (*models.ImageResponse, error) {
+func (s *Service) GetByUserIDImageID(ctx context.Context, uID, imageID string) (*models.ImageResponse, error) {
 
-	data, err := s.db.GetImage(uID, imageID)
+	data, err := s.db.GetImage(ctx, uID, imageID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.ImageResponse{
-		ImageID: data.ImageID,
-		TakenAt: data.TakenAt,
-		Path:    data.Path,
+	return toImageResponse(data), nil
+}
+
+func (s *Service) SetUserImageTags(ctx context.Context, uID, imageID string, tags []string) (*models.ImageResponse, error) {
+	err := s.db.SetImageTags(ctx, uID, imageID, normalizeTags(tags))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByUserIDImageID(ctx, uID, imageID)
+}
+
+func (s *Service) DeleteByUserIDImageID(ctx context.Context, uID, imageID string) error {
+	return s.parallelDeleteTasks(ctx, func() error { return s.s3.Delete(ctx, uID, imageID+requestparser.JPGImageExtension) }, func() error { return s.db.DeleteImage(ctx, uID, imageID) })
+}
+
+func (s *Service) DeleteAllByUserID(ctx context.Context, uID string) error {
+	return s.parallelDeleteTasks(ctx, func() error { return s.deleteAllFiles(ctx, uID) }, func() error { return s.db.DeleteAllImages(ctx, uID) })
+}
+
+// deleteAllFiles deletes the stored files of the user, failing if any file is left behind
+func (s *Service) deleteAllFiles(ctx context.Context, uID string) error {
+	result, err := s.s3.DeleteAll(ctx, uID)
+	if err != nil {
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("error listing user images"))
+	}
+	if err := result.Err(); err != nil {
+		s.log.For(ctx).Errorf("error deleting images of user %s :: %v", uID, err)
+		return errors.New(errors.ErrCodeGeneric, fmt.Errorf("%d user images could not be deleted", len(result.Failed)))
+	}
+	return nil
+}
+
+func (s *Service) GetUsage(ctx context.Context, uID string) (*models.UsageResponse, error) {
+	usage, err := s.db.GetUsage(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UsageResponse{
+		UsedBytes:  usage.UsedBytes,
+		ImageCount: usage.ImageCount,
+		MaxBytes:   s.quota.MaxBytes,
+		MaxImages:  s.quota.MaxImages,
 	}, nil
 }
 
-func (s *Service) DeleteByUserIDImageID(uID, imageID string) error {
-	return s.parallelDeleteTasks(func() error { return s.s3.Delete(uID, imageID+requestparser.JPGImageExtension) }, func() error { return s.db.DeleteImage(uID, imageID) })
+// releaseUsage gives back the usage reserved for an upload that failed.
+// It runs even if the request was cancelled, else the reservation would be lost.
+func (s *Service) releaseUsage(ctx context.Context, uID string, size int64) {
+	if err := s.db.ReleaseUsage(context.WithoutCancel(ctx), uID, size); err != nil {
+		s.log.For(ctx).Errorf("error releasing usage of %d bytes for user %s :: %v", size, uID, err)
+	}
+}
+
+func toImageResponse(ui *models.UserImage) *models.ImageResponse {
+	return &models.ImageResponse{
+		ImageID:  ui.ImageID,
+		TakenAt:  ui.TakenAt,
+		Path:     ui.Path,
+		Tags:     ui.Tags,
+		AlbumIDs: ui.AlbumIDs,
+	}
+}
+
+// normalizeTags lower cases and trims the tags, dropping empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	res := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = normalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		res = append(res, t)
+	}
+	return res
 }
 
-func (s *Service) DeleteAllByUserID(uID string) error {
-	return s.parallelDeleteTasks(func() error { return s.s3.DeleteAll(uID) }, func() error { return s.db.DeleteAllImages(uID) })
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
 }
 
-func (s *Service) parallelDeleteTasks(deleteFuncs ...func() error) error {
-	ctx := context.Background()
+// parallelDeleteTasks runs the delete funcs concurrently, the ones not yet started are skipped
+// once one failed or ctx is done
+func (s *Service) parallelDeleteTasks(ctx context.Context, deleteFuncs ...func() error) error {
 	g, ctx := errgroup.WithContext(ctx)
 
 	for _, deleteFunc := range deleteFuncs {
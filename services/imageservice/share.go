@@ -0,0 +1,195 @@
+package imageservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/rahul-aut-ind/service-user/domain/errors"
+	"github.com/rahul-aut-ind/service-user/domain/models"
+	"github.com/rahul-aut-ind/service-user/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type (
+	ShareService interface {
+		ShareUserImage(ctx context.Context, uID, imageID string, req *models.ShareRequest) (*models.ShareResponse, error)
+		GetAllShares(ctx context.Context, uID, imageID string) (*models.ShareListResponse, error)
+		RevokeShare(ctx context.Context, uID, shareID string) error
+		GetSharedUserImage(ctx context.Context, uID, ownerID, imageID string) (*models.ImageResponse, error)
+		ResolveShareLink(ctx context.Context, token, password string) (string, error)
+	}
+)
+
+const (
+	// MaxShareLinkTTL is the longest a public share link may stay valid
+	MaxShareLinkTTL = 30 * 24 * time.Hour
+	// PresignedURLExpiry is how long the url of a shared image stays valid
+	PresignedURLExpiry = 5 * time.Minute
+	shareTokenBytes    = 32
+)
+
+// ShareUserImage grants read access to the image to another user, or creates a public link if no user is given
+func (s *Service) ShareUserImage(ctx context.Context, uID, imageID string, req *models.ShareRequest) (*models.ShareResponse, error) {
+	if _, err := s.db.GetImage(ctx, uID, imageID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("share expiry must be in the future"))
+	}
+
+	share := &models.Share{
+		OwnerID:   uID,
+		ImageID:   imageID,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+
+	if req.UserID != "" {
+		if req.UserID == uID {
+			return nil, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("image can not be shared with its owner"))
+		}
+		if req.Password != "" {
+			return nil, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("password is only supported for share links"))
+		}
+		share.ShareID = grantID(uID, imageID, req.UserID)
+		share.GranteeID = req.UserID
+	} else {
+		if req.ExpiresAt == nil || req.ExpiresAt.Sub(now) > MaxShareLinkTTL {
+			return nil, errors.New(errors.ErrCodeBadRequest, fmt.Errorf("share link can be valid for at most %s", MaxShareLinkTTL))
+		}
+		token, err := newShareToken()
+		if err != nil {
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("share token generation failed"))
+		}
+		share.ShareID = token
+	}
+
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("password hashing failed"))
+		}
+		share.PasswordHash = string(hash)
+	}
+	if share.ExpiresAt != nil {
+		share.TTL = share.ExpiresAt.Unix()
+	}
+
+	if err := s.db.CreateShare(ctx, share); err != nil {
+		return nil, err
+	}
+
+	return toShareResponse(share), nil
+}
+
+func (s *Service) GetAllShares(ctx context.Context, uID, imageID string) (*models.ShareListResponse, error) {
+	shares, err := s.db.ListShares(ctx, uID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	r := make([]models.ShareResponse, 0, len(shares))
+	for i := range shares {
+		// expired links linger until dynamoDB purges them
+		if shares[i].IsExpired(now) {
+			continue
+		}
+		r = append(r, *toShareResponse(&shares[i]))
+	}
+
+	return &models.ShareListResponse{Shares: r}, nil
+}
+
+func (s *Service) RevokeShare(ctx context.Context, uID, shareID string) error {
+	return s.db.DeleteShare(ctx, uID, shareID)
+}
+
+// GetSharedUserImage returns an image of another user, if it was shared with the requesting user
+func (s *Service) GetSharedUserImage(ctx context.Context, uID, ownerID, imageID string) (*models.ImageResponse, error) {
+	share, err := s.db.GetShare(ctx, grantID(ownerID, imageID, uID))
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("image not found"))
+	}
+	if share.IsExpired(time.Now()) {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Errorf("image not found"))
+	}
+
+	image, err := s.db.GetImage(ctx, ownerID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toImageResponse(image)
+	// album membership is private to the owner
+	res.AlbumIDs = nil
+	res.URL, err = s.s3.GetPresignedURL(ctx, image.Path, PresignedURLExpiry)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeGeneric, fmt.Errorf("error presigning image url"))
+	}
+
+	return res, nil
+}
+
+// ResolveShareLink checks a public link and returns a pre-signed url of the shared image
+func (s *Service) ResolveShareLink(ctx context.Context, token, password string) (string, error) {
+	share, err := s.db.GetShare(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	// unknown, expired and user grants all look the same to the public
+	if !share.IsPublicLink() || share.IsExpired(time.Now()) {
+		return "", errors.New(errors.ErrCodeNotFound, fmt.Errorf("share not found"))
+	}
+
+	if share.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(share.PasswordHash), []byte(password)); err != nil {
+			return "", errors.New(errors.ErrCodeUnauthorized, fmt.Errorf("invalid share password"))
+		}
+	}
+
+	image, err := s.db.GetImage(ctx, share.OwnerID, share.ImageID)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := s.s3.GetPresignedURL(ctx, image.Path, PresignedURLExpiry)
+	if err != nil {
+		return "", errors.New(errors.ErrCodeGeneric, fmt.Errorf("error presigning image url"))
+	}
+
+	return url, nil
+}
+
+// grantID is the share id of an image shared with another user, so a grant can be looked up directly
+func grantID(ownerID, imageID, granteeID string) string {
+	return fmt.Sprintf("g-%s-%s-%s", ownerID, imageID, granteeID)
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func toShareResponse(sh *models.Share) *models.ShareResponse {
+	res := &models.ShareResponse{
+		ShareID:     sh.ShareID,
+		ImageID:     sh.ImageID,
+		UserID:      sh.GranteeID,
+		ExpiresAt:   sh.ExpiresAt,
+		HasPassword: sh.PasswordHash != "",
+		CreatedAt:   sh.CreatedAt,
+	}
+	if sh.IsPublicLink() {
+		res.URL = fmt.Sprintf("%s/%s", config.PublicSharePath, sh.ShareID)
+	}
+	return res
+}
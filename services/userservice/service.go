@@ -1,6 +1,7 @@
 package userservice
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rahul-aut-ind/service-user/domain/models"
@@ -10,12 +11,12 @@ import (
 
 type (
 	UserService interface {
-		GetUserWithID(id string) (*models.User, error)
-		GetAllUsers() ([]models.User, error)
-		AddUser(u *models.User) (*models.User, error)
-		UpdateUser(id string, u *models.User) (*models.User, error)
-		DeleteUser(id string) error
-		UploadProfilePicture(id string) error
+		GetUserWithID(ctx context.Context, id string) (*models.User, error)
+		GetAllUsers(ctx context.Context) ([]models.User, error)
+		AddUser(ctx context.Context, u *models.User) (*models.User, error)
+		UpdateUser(ctx context.Context, id string, u *models.User) (*models.User, error)
+		DeleteUser(ctx context.Context, id string) error
+		UploadProfilePicture(ctx context.Context, id string) error
 	}
 
 	Service struct {
@@ -28,62 +29,72 @@ func New(r mysqlrepo.DataHandler, l *logger.Logger) *Service {
 	return &Service{db: r, log: l}
 }
 
-func (s *Service) AddUser(user *models.User) (*models.User, error) {
-	res, err := s.db.CreateRecord(user)
+// primary returns the repo reading from the primary database, for reads a following write depends on
+func (s *Service) primary() mysqlrepo.DataHandler {
+	if p, ok := s.db.(mysqlrepo.PrimaryPinner); ok {
+		return p.Primary()
+	}
+	return s.db
+}
+
+func (s *Service) AddUser(ctx context.Context, user *models.User) (*models.User, error) {
+	res, err := s.db.CreateRecord(ctx, user)
 	if err != nil {
 		msg := fmt.Sprintf("error creating user :: %s", err.Error())
-		s.log.Errorf(msg)
+		s.log.For(ctx).Errorf(msg)
 		return nil, fmt.Errorf("%s", msg)
 	}
 	return res, nil
 }
 
-func (s *Service) GetUserWithID(id string) (*models.User, error) {
-	res, err := s.db.FindRecord(id)
+func (s *Service) GetUserWithID(ctx context.Context, id string) (*models.User, error) {
+	res, err := s.db.FindRecord(ctx, id)
 	if err != nil {
 		msg := fmt.Sprintf("error :: %s", err.Error())
-		s.log.Errorf(msg)
+		s.log.For(ctx).Errorf(msg)
 		return nil, fmt.Errorf("%s", msg)
 	}
 
 	return res, nil
 }
 
-func (s *Service) DeleteUser(id string) error {
-	res, err := s.db.FindRecord(id)
+func (s *Service) DeleteUser(ctx context.Context, id string) error {
+	db := s.primary()
+	res, err := db.FindRecord(ctx, id)
 	if err != nil {
 		msg := fmt.Sprintf("error :: %s", err.Error())
-		s.log.Errorf(msg)
+		s.log.For(ctx).Errorf(msg)
 		return fmt.Errorf("%s", msg)
 	}
 
-	res, err = s.db.DeleteRecord(res)
+	res, err = db.DeleteRecord(ctx, res)
 	if err != nil {
 		msg := fmt.Sprintf("error deleting user %s :: %s", id, err.Error())
-		s.log.Errorf(msg)
+		s.log.For(ctx).Errorf(msg)
 		return fmt.Errorf("%s", msg)
 	}
-	s.log.Debugf("deleted user %d", res.ID)
+	s.log.For(ctx).Debugf("deleted user %d", res.ID)
 
 	return nil
 }
 
-func (s *Service) GetAllUsers() ([]models.User, error) {
-	res, err := s.db.ListRecords()
+func (s *Service) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	res, err := s.db.ListRecords(ctx)
 	if err != nil {
 		msg := fmt.Sprintf("error getting all users :: %s", err.Error())
-		s.log.Errorf(msg)
+		s.log.For(ctx).Errorf(msg)
 		return nil, fmt.Errorf("%s", msg)
 	}
 
 	return res, nil
 }
 
-func (s *Service) UpdateUser(id string, u *models.User) (*models.User, error) {
-	rec, err := s.db.FindRecord(id)
+func (s *Service) UpdateUser(ctx context.Context, id string, u *models.User) (*models.User, error) {
+	db := s.primary()
+	rec, err := db.FindRecord(ctx, id)
 	if err != nil {
 		msg := fmt.Sprintf("error :: %s", err.Error())
-		s.log.Errorf(msg)
+		s.log.For(ctx).Errorf(msg)
 		return nil, fmt.Errorf("%s", msg)
 	}
 
@@ -92,17 +103,17 @@ func (s *Service) UpdateUser(id string, u *models.User) (*models.User, error) {
 	// updating of email should be prohibited, maybe is used for login
 	u.Email = rec.Email
 
-	res, err := s.db.UpdateRecord(u)
+	res, err := db.UpdateRecord(ctx, u)
 	if err != nil {
 		msg := fmt.Sprintf("error updating user %s :: %s", id, err.Error())
-		s.log.Errorf(msg)
+		s.log.For(ctx).Errorf(msg)
 		return nil, fmt.Errorf("%s", msg)
 	}
 
 	return res, nil
 }
 
-func (s *Service) UploadProfilePicture(id string) error {
-	s.log.Debugf("uploading profile pic with id %s", id)
+func (s *Service) UploadProfilePicture(ctx context.Context, id string) error {
+	s.log.For(ctx).Debugf("uploading profile pic with id %s", id)
 	return nil
 }